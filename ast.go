@@ -0,0 +1,159 @@
+package te
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kind identifies the constructor of an expression.
+type Kind int
+
+// The kinds of expression. The arguments of a Node are those of the
+// constructor of the same name, in order, with the types noted.
+const (
+	KindOther         Kind = iota // expression implemented outside of this package
+	KindNil                       // invalid expression
+	KindHour                      // hour int
+	KindHourStep                  // n, offset int
+	KindMinute                    // min int
+	KindMinuteStep                // n, offset int
+	KindSecond                    // sec int
+	KindSecondStep                // n, offset int
+	KindMillisecond               // ms int
+	KindMillisecondly             // n int
+	KindMicrosecond               // us int
+	KindMicrosecondly             // n int
+	KindEvery                     // d time.Duration, anchor time.Time
+	KindDay                       // n int
+	KindDaily                     // no arguments
+	KindWeekday                   // d time.Weekday
+	KindMonth                     // month time.Month
+	KindYear                      // year int
+	KindDateRange                 // m1 time.Month, d1 int, m2 time.Month, d2 int
+	KindTimeRange                 // h1, m1, s1, h2, m2, s2 int
+	KindIn                        // loc *time.Location, with one child
+	KindUnion                     // children only
+	KindIntersect                 // children only
+	KindExcept                    // children only
+)
+
+var kindNames = []string{
+	KindOther:         "Other",
+	KindNil:           "Nil",
+	KindHour:          "Hour",
+	KindHourStep:      "HourStep",
+	KindMinute:        "Minute",
+	KindMinuteStep:    "MinuteStep",
+	KindSecond:        "Second",
+	KindSecondStep:    "SecondStep",
+	KindMillisecond:   "Millisecond",
+	KindMillisecondly: "Millisecondly",
+	KindMicrosecond:   "Microsecond",
+	KindMicrosecondly: "Microsecondly",
+	KindEvery:         "Every",
+	KindDay:           "Day",
+	KindDaily:         "Daily",
+	KindWeekday:       "Weekday",
+	KindMonth:         "Month",
+	KindYear:          "Year",
+	KindDateRange:     "DateRange",
+	KindTimeRange:     "TimeRange",
+	KindIn:            "In",
+	KindUnion:         "Union",
+	KindIntersect:     "Intersect",
+	KindExcept:        "Except",
+}
+
+func (k Kind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Node is a read-only view of an expression.
+type Node struct {
+	Kind     Kind
+	Args     []interface{} // constructor arguments, see Kind
+	Children []Expression  // operands of In, Union, Intersect and Except
+	Policy   Policy        // daylight saving time policy, see WithPolicy
+	Err      error         // reason for an invalid expression, if known
+}
+
+// Inspect returns a view of the outermost expression of expr.
+// Expressions implemented outside of this package are of KindOther.
+func Inspect(expr Expression) Node {
+	switch e := expr.(type) {
+	case nilExpr:
+		return Node{Kind: KindNil, Err: e.err}
+	case hourExpr:
+		return Node{Kind: KindHour, Args: args(e.hour), Policy: e.policy}
+	case hourlyExpr:
+		return Node{Kind: KindHourStep, Args: args(e.n, e.offset), Policy: e.policy}
+	case minuteExpr:
+		return Node{Kind: KindMinute, Args: args(e.min), Policy: e.policy}
+	case minutelyExpr:
+		return Node{Kind: KindMinuteStep, Args: args(e.n, e.offset), Policy: e.policy}
+	case secondExpr:
+		return Node{Kind: KindSecond, Args: args(int(e))}
+	case secondlyExpr:
+		return Node{Kind: KindSecondStep, Args: args(e.n, e.offset)}
+	case millisecondExpr:
+		return Node{Kind: KindMillisecond, Args: args(int(e))}
+	case millisecondlyExpr:
+		return Node{Kind: KindMillisecondly, Args: args(e.n)}
+	case microsecondExpr:
+		return Node{Kind: KindMicrosecond, Args: args(int(e))}
+	case microsecondlyExpr:
+		return Node{Kind: KindMicrosecondly, Args: args(e.n)}
+	case everyExpr:
+		return Node{Kind: KindEvery, Args: args(e.d, e.anchor)}
+	case dayExpr:
+		return Node{Kind: KindDay, Args: args(int(e))}
+	case dailyExpr:
+		return Node{Kind: KindDaily}
+	case weekdayExpr:
+		return Node{Kind: KindWeekday, Args: args(time.Weekday(e))}
+	case monthExpr:
+		return Node{Kind: KindMonth, Args: args(time.Month(e))}
+	case yearExpr:
+		return Node{Kind: KindYear, Args: args(int(e))}
+	case dateRangeExpr:
+		return Node{Kind: KindDateRange, Args: args(e.t1.Month(), e.t1.Day(), e.t2.Month(), e.t2.Day())}
+	case timeRangeExpr:
+		h1, m1, s1 := e.t1.Clock()
+		h2, m2, s2 := e.t2.Clock()
+		return Node{Kind: KindTimeRange, Args: args(h1, m1, s1, h2, m2, s2), Policy: e.policy}
+	case locationExpr:
+		return Node{Kind: KindIn, Args: args(e.loc), Children: []Expression{e.expr}}
+	case unionExpr:
+		return Node{Kind: KindUnion, Children: children(e)}
+	case intersectExpr:
+		return Node{Kind: KindIntersect, Children: children(e)}
+	case exceptExpr:
+		return Node{Kind: KindExcept, Children: children(e)}
+	}
+	return Node{Kind: KindOther}
+}
+
+// Walk traverses expr in depth-first order. It calls fn with expr and,
+// if fn returns true, walks each of the children of expr in turn.
+func Walk(expr Expression, fn func(Expression) bool) {
+	if !fn(expr) {
+		return
+	}
+	for _, e := range Inspect(expr).Children {
+		Walk(e, fn)
+	}
+}
+
+func args(v ...interface{}) []interface{} {
+	return v
+}
+
+// children returns a copy of exprs so that the view is read-only.
+func children(exprs []Expression) []Expression {
+	rv := make([]Expression, len(exprs))
+	copy(rv, exprs)
+	return rv
+}
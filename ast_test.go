@@ -0,0 +1,111 @@
+package te
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type otherExpr struct{}
+
+func (otherExpr) IsActive(t time.Time) bool  { return false }
+func (otherExpr) Next(t time.Time) time.Time { return time.Time{} }
+
+func TestInspect(t *testing.T) {
+	anchor := time.Date(2016, 1, 1, 0, 5, 0, 0, time.UTC)
+	skip := Policy{Gap: GapSkip}
+	tests := map[string]struct {
+		expr Expression
+		want Node
+	}{
+		"hour": {
+			Hour(15),
+			Node{Kind: KindHour, Args: []interface{}{15}},
+		},
+		"hourly": {
+			Hourly(2),
+			Node{Kind: KindHourStep, Args: []interface{}{2, 0}},
+		},
+		"minute step": {
+			WithPolicy(MinuteStep(15, 5), skip),
+			Node{Kind: KindMinuteStep, Args: []interface{}{15, 5}, Policy: skip},
+		},
+		"second": {
+			Second(30),
+			Node{Kind: KindSecond, Args: []interface{}{30}},
+		},
+		"every": {
+			Every(7*time.Minute, anchor),
+			Node{Kind: KindEvery, Args: []interface{}{7 * time.Minute, anchor}},
+		},
+		"daily": {
+			Daily(),
+			Node{Kind: KindDaily},
+		},
+		"weekday": {
+			Weekday(time.Monday),
+			Node{Kind: KindWeekday, Args: []interface{}{time.Monday}},
+		},
+		"date range": {
+			DateRange(time.March, 1, time.May, 31),
+			Node{Kind: KindDateRange, Args: []interface{}{time.March, 1, time.May, 31}},
+		},
+		"time range": {
+			TimeRange(9, 0, 0, 17, 30, 0),
+			Node{Kind: KindTimeRange, Args: []interface{}{9, 0, 0, 17, 30, 0}},
+		},
+		"in": {
+			In(Hour(9), time.UTC),
+			Node{Kind: KindIn, Args: []interface{}{time.UTC}, Children: []Expression{Hour(9)}},
+		},
+		"intersect": {
+			Intersect(Hour(9), Weekday(time.Monday)),
+			Node{Kind: KindIntersect, Children: []Expression{Hour(9), Weekday(time.Monday)}},
+		},
+		"nil": {
+			Hour(25),
+			Node{Kind: KindNil, Err: &RangeError{Func: "Hour", Arg: "hour", Value: 25, Min: 0, Max: 23}},
+		},
+		"other": {
+			otherExpr{},
+			Node{Kind: KindOther},
+		},
+	}
+	for name, tt := range tests {
+		have := Inspect(tt.expr)
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("%s\nhave %#v\nwant %#v", name, have, tt.want)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	expr := Union(
+		Intersect(Weekday(time.Monday), Hour(9)),
+		In(Intersect(Weekday(time.Friday), Hour(17)), time.UTC),
+	)
+	var have []Kind
+	Walk(expr, func(e Expression) bool {
+		n := Inspect(e)
+		have = append(have, n.Kind)
+		return n.Kind != KindIn
+	})
+	want := []Kind{KindUnion, KindIntersect, KindWeekday, KindHour, KindIn}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v\nwant %v", have, want)
+	}
+}
+
+func TestKindString(t *testing.T) {
+	tests := map[Kind]string{
+		KindHourStep: "HourStep",
+		KindExcept:   "Except",
+		Kind(-1):     "Kind(-1)",
+		Kind(100):    "Kind(100)",
+	}
+	for k, want := range tests {
+		if have := k.String(); have != want {
+			t.Errorf("have %q\nwant %q", have, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+// active reports whether an expression is active at the reference time.
+// It exits with status 0 if it is active, 1 if it is not and 2 on error,
+// so that it may be used as a condition in shell scripts.
+func active(args []string) error {
+	var o options
+	var quiet bool
+	fs := newFlagSet("te active", "[OPTIONS] EXPR")
+	o.register(fs)
+	fs.BoolVar(&quiet, "q", false, "do not print anything, only set the exit status")
+	fs.BoolVar(&quiet, "quiet", false, "do not print anything, only set the exit status")
+	f := fs.String("f", "Mon Jan 2 15:04 MST", "time format layout")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	e, _, t, err := o.parse(fs.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return exitError(2)
+	}
+	w := io.Writer(os.Stdout)
+	if quiet {
+		w = ioutil.Discard
+	}
+	if !isActive(w, e, t, *f) {
+		return exitError(1)
+	}
+	return nil
+}
+
+// isActive reports whether expr is active at t and writes a description
+// of the result, including the next occurrence if it is not, to w.
+func isActive(w io.Writer, expr te.Expression, t time.Time, layout string) bool {
+	if expr.IsActive(t) {
+		fmt.Fprintf(w, "active at %s\n", t.Format(layout))
+		return true
+	}
+	next := expr.Next(t)
+	if next.IsZero() {
+		fmt.Fprintf(w, "inactive at %s, never active again\n", t.Format(layout))
+		return false
+	}
+	fmt.Fprintf(w, "inactive at %s, next active at %s\n", t.Format(layout), next.Format(layout))
+	return false
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+func TestIsActive(t *testing.T) {
+	window := te.Intersect(
+		te.Union(te.Weekday(time.Saturday), te.Weekday(time.Sunday)),
+		te.Union(te.Hour(1), te.Hour(2), te.Hour(3), te.Hour(4)),
+	)
+	tests := map[string]struct {
+		expr te.Expression
+		t    time.Time
+		want bool
+		out  string
+	}{
+		"active": {
+			window,
+			time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC),
+			true,
+			"active at Sun Mar 1 02:30 UTC\n",
+		},
+		"inactive": {
+			window,
+			time.Date(2026, 3, 1, 5, 0, 0, 0, time.UTC),
+			false,
+			"inactive at Sun Mar 1 05:00 UTC, next active at Sat Mar 7 01:00 UTC\n",
+		},
+		"never": {
+			te.Year(2025),
+			time.Date(2026, 3, 1, 5, 0, 0, 0, time.UTC),
+			false,
+			"inactive at Sun Mar 1 05:00 UTC, never active again\n",
+		},
+	}
+	for name, tt := range tests {
+		var buf bytes.Buffer
+		have := isActive(&buf, tt.expr, tt.t, "Mon Jan 2 15:04 MST")
+		if have != tt.want || buf.String() != tt.out {
+			t.Errorf("%s\nhave %v %q\nwant %v %q", name, have, buf.String(), tt.want, tt.out)
+		}
+	}
+}
+
+func TestIsActiveParsed(t *testing.T) {
+	expr, err := te.Parse("Sat/Sun between 1am and 5am", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[time.Time]bool{
+		time.Date(2026, 2, 28, 0, 59, 0, 0, time.UTC): false,
+		time.Date(2026, 2, 28, 1, 0, 0, 0, time.UTC):  true,
+		time.Date(2026, 3, 1, 4, 59, 0, 0, time.UTC):  true,
+		time.Date(2026, 3, 1, 5, 1, 0, 0, time.UTC):   false,
+		time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC):   false,
+	}
+	for at, want := range tests {
+		have := isActive(ioutil.Discard, expr, at, time.RFC3339)
+		if have != want {
+			t.Errorf("%v\nhave %v\nwant %v", at, have, want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+// maxDayCount is the most occurrences counted on a single day.
+const maxDayCount = 10000
+
+// cal prints a calendar of the days an expression is active.
+func cal(args []string) error {
+	var o options
+	fs := newFlagSet("te cal", "[OPTIONS] EXPR")
+	o.register(fs)
+	m := fs.Int("m", 1, "number of months to show")
+	y := fs.Bool("y", false, "show the whole year")
+	monday := fs.Bool("monday", false, "start weeks on Monday")
+	color := fs.String("color", "auto", "highlight active days: auto, always or never")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	e, loc, t, err := o.parse(fs.Args())
+	if err != nil {
+		return err
+	}
+	c := calendar{expr: e, loc: loc, monday: *monday}
+	switch *color {
+	case "auto":
+		c.color = isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+	case "always":
+		c.color = true
+	case "never":
+	default:
+		return fmt.Errorf("invalid color %q", *color)
+	}
+	year, month, _ := t.Date()
+	n := *m
+	if *y {
+		month, n = time.January, 12
+	}
+	if n < 1 {
+		return fmt.Errorf("invalid number of months %d", n)
+	}
+	c.render(os.Stdout, year, month, n)
+	return nil
+}
+
+// calendar renders month grids highlighting the days an expression is
+// active, followed by the number of occurrences on those days.
+type calendar struct {
+	expr   te.Expression
+	loc    *time.Location
+	color  bool
+	monday bool
+}
+
+// calMonth is a rendered month and the occurrences on each of its days.
+type calMonth struct {
+	first  time.Time
+	lines  []string
+	counts []int
+}
+
+const (
+	calWidth = 7 * 3 // width of a rendered month
+	calCols  = 3     // months rendered side by side
+)
+
+func (c calendar) render(w io.Writer, year int, month time.Month, n int) {
+	months := make([]calMonth, n)
+	for i := range months {
+		months[i] = c.month(time.Date(year, month+time.Month(i), 1, 0, 0, 0, 0, c.loc))
+	}
+	for i := 0; i < len(months); i += calCols {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		row := months[i:min(i+calCols, len(months))]
+		rows := 0
+		for _, m := range row {
+			rows = max(rows, len(m.lines))
+		}
+		for j := 0; j < rows; j++ {
+			var line []string
+			for _, m := range row {
+				cell := strings.Repeat(" ", calWidth)
+				if j < len(m.lines) {
+					cell = m.lines[j]
+				}
+				line = append(line, cell)
+			}
+			fmt.Fprintln(w, strings.TrimRight(strings.Join(line, " "), " "))
+		}
+	}
+	fmt.Fprintln(w)
+	for _, m := range months {
+		c.summarize(w, m)
+	}
+}
+
+// month renders the grid of the month beginning at first.
+func (c calendar) month(first time.Time) calMonth {
+	m := calMonth{first: first}
+	title := first.Format("January 2006")
+	pad := (calWidth - 1 - len(title)) / 2
+	m.lines = append(m.lines, fmt.Sprintf("%-*s", calWidth, strings.Repeat(" ", pad)+title))
+	var header strings.Builder
+	for i := 0; i < 7; i++ {
+		header.WriteString(c.weekday(i).String()[:2] + " ")
+	}
+	m.lines = append(m.lines, header.String())
+	var week strings.Builder
+	cells := (int(first.Weekday()) - int(c.weekday(0)) + 7) % 7
+	week.WriteString(strings.Repeat(" ", cells*3))
+	for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+		count := occurrences(c.expr, day, day.AddDate(0, 0, 1))
+		m.counts = append(m.counts, count)
+		week.WriteString(c.cell(day.Day(), count))
+		cells++
+		if cells == 7 {
+			m.lines = append(m.lines, week.String())
+			week.Reset()
+			cells = 0
+		}
+	}
+	if cells > 0 {
+		week.WriteString(strings.Repeat(" ", (7-cells)*3))
+		m.lines = append(m.lines, week.String())
+	}
+	return m
+}
+
+// weekday returns the weekday of the i-th column.
+func (c calendar) weekday(i int) time.Weekday {
+	if c.monday {
+		i++
+	}
+	return time.Weekday(i % 7)
+}
+
+// cell returns a day of the grid. Active days are highlighted with ANSI
+// escapes, bolder when the expression occurs more than once that day, or
+// marked with an asterisk without color.
+func (c calendar) cell(day, count int) string {
+	switch {
+	case count == 0:
+		return fmt.Sprintf("%2d ", day)
+	case !c.color:
+		return fmt.Sprintf("%2d*", day)
+	case count == 1:
+		return fmt.Sprintf("\x1b[7m%2d\x1b[0m ", day)
+	}
+	return fmt.Sprintf("\x1b[1;7m%2d\x1b[0m ", day)
+}
+
+// summarize writes the number of occurrences in a month. Each active day
+// is listed unless there are more than a week of them.
+func (c calendar) summarize(w io.Writer, m calMonth) {
+	var days, total, first int
+	same, capped := true, false
+	for _, count := range m.counts {
+		if count == 0 {
+			continue
+		}
+		if days == 0 {
+			first = count
+		}
+		same = same && count == first
+		capped = capped || count >= maxDayCount
+		days++
+		total += count
+	}
+	title := m.first.Format("January 2006")
+	if days == 0 {
+		fmt.Fprintf(w, "%s: never active\n", title)
+		return
+	}
+	fmt.Fprintf(w, "%s: %s, %s", title, plural(days, "day"), times(total, capped))
+	if days > 7 {
+		if same {
+			fmt.Fprintf(w, " (%s a day)", times(first, capped))
+		}
+		fmt.Fprintln(w)
+		return
+	}
+	fmt.Fprintln(w)
+	for i, count := range m.counts {
+		if count > 0 {
+			day := m.first.AddDate(0, 0, i)
+			fmt.Fprintf(w, "  %s  %s\n", day.Format("Mon Jan _2"), times(count, count >= maxDayCount))
+		}
+	}
+}
+
+// occurrences returns the number of times expr occurs in [start, end),
+// counting an expression already active at start once. The count stops
+// at maxDayCount.
+func occurrences(expr te.Expression, start, end time.Time) int {
+	n := 0
+	t := expr.Next(start.Add(-time.Nanosecond))
+	if expr.IsActive(start) && !t.Equal(start) {
+		n++
+	}
+	for ; !t.IsZero() && t.Before(end) && n < maxDayCount; t = expr.Next(t) {
+		n++
+	}
+	return n
+}
+
+// times returns the number of occurrences n, which is a lower bound if
+// the count on a day was capped.
+func times(n int, capped bool) string {
+	if capped {
+		return fmt.Sprintf("%d+ times", n)
+	}
+	return plural(n, "time")
+}
+
+func plural(n int, s string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, s)
+	}
+	return fmt.Sprintf("%d %ss", n, s)
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
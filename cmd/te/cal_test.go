@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+func TestOccurrences(t *testing.T) {
+	start := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+	tests := map[string]struct {
+		expr te.Expression
+		want int
+	}{
+		"none":       {te.Weekday(time.Monday), 0},
+		"whole day":  {te.Weekday(time.Tuesday), 1},
+		"continuing": {te.Month(time.March), 1},
+		"twice":      {te.Union(te.Hour(9), te.Hour(17)), 2},
+		"capped":     {te.Secondly(1), maxDayCount},
+	}
+	for name, tt := range tests {
+		have := occurrences(tt.expr, start, end)
+		if have != tt.want {
+			t.Errorf("%s\nhave %d\nwant %d", name, have, tt.want)
+		}
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	var buf bytes.Buffer
+	expr := te.Intersect(te.Weekday(time.Tuesday), te.Union(te.Hour(9), te.Hour(17)))
+	c := calendar{expr: expr, loc: time.UTC, monday: true}
+	c.render(&buf, 2026, time.February, 2)
+	want := `   February 2026           March 2026
+Mo Tu We Th Fr Sa Su  Mo Tu We Th Fr Sa Su
+                   1                     1
+ 2  3* 4  5  6  7  8   2  3* 4  5  6  7  8
+ 9 10*11 12 13 14 15   9 10*11 12 13 14 15
+16 17*18 19 20 21 22  16 17*18 19 20 21 22
+23 24*25 26 27 28     23 24*25 26 27 28 29
+                      30 31*
+
+February 2026: 4 days, 8 times
+  Tue Feb  3  2 times
+  Tue Feb 10  2 times
+  Tue Feb 17  2 times
+  Tue Feb 24  2 times
+March 2026: 5 days, 10 times
+  Tue Mar  3  2 times
+  Tue Mar 10  2 times
+  Tue Mar 17  2 times
+  Tue Mar 24  2 times
+  Tue Mar 31  2 times
+`
+	if buf.String() != want {
+		t.Errorf("have\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+// syntaxes are the schedule languages that may be converted between.
+var syntaxes = map[string]struct {
+	parse  func(string) (spec, error)
+	format func(spec) (string, error)
+}{
+	"cron":    {parseCron, formatCron},
+	"systemd": {parseSystemd, formatSystemd},
+	"rrule":   {parseRRule, formatRRule},
+	"te":      {nil, formatText},
+}
+
+// convert translates a schedule between te, cron, systemd calendar
+// events and recurrence rules. Conversions that cannot be made exactly
+// are an error.
+func convert(args []string) error {
+	fs := newFlagSet("te convert", "-from SYNTAX -to SYNTAX [OPTIONS] SCHEDULE")
+	from := fs.String("from", "te", "syntax of the schedule: te, cron, systemd or rrule")
+	to := fs.String("to", "te", "syntax to convert to: te, cron, systemd or rrule")
+	l := fs.String("l", "Local", "expression timezone location")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(*l)
+	if err != nil {
+		return err
+	}
+	s, err := convertSchedule(strings.Join(fs.Args(), " "), *from, *to, loc, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, s)
+	return nil
+}
+
+// convertSchedule converts s between syntaxes. The result is checked to
+// occur at the same times as s after t in loc.
+func convertSchedule(s, from, to string, loc *time.Location, t time.Time) (string, error) {
+	src, ok := syntaxes[from]
+	if !ok {
+		return "", fmt.Errorf("invalid syntax %q, expected te, cron, systemd or rrule", from)
+	}
+	dst, ok := syntaxes[to]
+	if !ok {
+		return "", fmt.Errorf("invalid syntax %q, expected te, cron, systemd or rrule", to)
+	}
+	var sp spec
+	var want te.Expression
+	var err error
+	if from == "te" {
+		want, err = te.Parse(s, loc)
+		if err != nil {
+			return "", err
+		}
+		sp, err = specOf(want)
+		if err != nil {
+			return "", fmt.Errorf("cannot convert to %s: %v", to, err)
+		}
+	} else {
+		sp, err = src.parse(s)
+		if err != nil {
+			return "", err
+		}
+		want = sp.expr()
+	}
+	rv, err := dst.format(sp)
+	if err != nil {
+		return "", fmt.Errorf("cannot convert to %s: %v", to, err)
+	}
+	var have te.Expression
+	if to == "te" {
+		have, err = te.Parse(rv, loc)
+	} else {
+		var out spec
+		out, err = dst.parse(rv)
+		have = out.expr()
+	}
+	if err != nil || !equivalent(want, have, t.In(loc)) {
+		return "", fmt.Errorf("cannot convert to %s: %q is not equivalent", to, rv)
+	}
+	return rv, nil
+}
+
+// maxTimes is the most times of day written out in te syntax.
+const maxTimes = 48
+
+// formatText formats a spec in te syntax.
+func formatText(sp spec) (string, error) {
+	var h, m, s []int
+	from := sp.finest()
+	if from <= levelHour {
+		h = sp.hour
+	}
+	if from <= levelMinute {
+		m = sp.minute
+	}
+	if from <= levelSecond {
+		s = sp.second
+	}
+	clauses, err := textTimes(h, m, s, from == levelSecond && s == nil)
+	if err != nil {
+		return "", err
+	}
+	if from <= levelDate {
+		days, err := textDays(sp)
+		if err != nil {
+			return "", err
+		}
+		clauses = append(clauses, days...)
+	}
+	if sp.year != nil {
+		clauses = append(clauses, textList(sp.year, strconv.Itoa))
+	}
+	if sp.month != nil && from <= levelMonth {
+		clauses = append(clauses, textList(sp.month, func(v int) string {
+			return strings.ToLower(time.Month(v).String()[:3])
+		}))
+	}
+	return strings.Join(clauses, " "), nil
+}
+
+// textTimes returns the clauses for the hours, minutes and seconds of a
+// spec. Steps are written as such and other values as times of day.
+func textTimes(h, m, s []int, everySecond bool) ([]string, error) {
+	var clauses []string
+	if len(h) == 1 && len(m) == 1 && s == nil && !everySecond {
+		return []string{fmt.Sprintf("%02d:%02d", h[0], m[0])}, nil
+	}
+	if len(h) == 1 && len(m) == 1 && len(s) == 1 {
+		return []string{fmt.Sprintf("%02d:%02d:%02d", h[0], m[0], s[0])}, nil
+	}
+	hours := h
+	if hours == nil {
+		hours = steps(1, 0, 23)
+	}
+	switch _, _, ok := progression(s, 59); {
+	case everySecond:
+		clauses = append(clauses, "every second")
+	case s == nil:
+	case equal(s, 0) && m == nil:
+		clauses = append(clauses, "every minute")
+	case ok:
+		clauses = append(clauses, textStep(s, 59, "seconds", func(v int) string { return fmt.Sprintf(":%02d", v) }))
+	default:
+		if m == nil {
+			return nil, errors.New("te syntax has no seconds of every minute")
+		}
+		var times []string
+		for _, hh := range hours {
+			for _, mm := range m {
+				for _, ss := range s {
+					times = append(times, fmt.Sprintf("%02d:%02d:%02d", hh, mm, ss))
+				}
+			}
+		}
+		return append([]string{strings.Join(times, "/")}, clauses...), checkTimes(times)
+	}
+	switch _, _, ok := progression(m, 59); {
+	case m == nil:
+	case equal(m, 0) && h == nil:
+		clauses = append([]string{"every hour"}, clauses...)
+	case ok:
+		clauses = append([]string{textStep(m, 59, "minutes", func(v int) string { return fmt.Sprintf(":%02d", v) })}, clauses...)
+	default:
+		var times []string
+		for _, hh := range hours {
+			for _, mm := range m {
+				times = append(times, fmt.Sprintf("%02d:%02d", hh, mm))
+			}
+		}
+		return append([]string{strings.Join(times, "/")}, clauses...), checkTimes(times)
+	}
+	switch _, _, ok := progression(h, 23); {
+	case h == nil:
+	case ok:
+		clauses = append([]string{textStep(h, 23, "hours", func(v int) string { return fmt.Sprintf("%02d:00", v) })}, clauses...)
+	default:
+		clauses = append([]string{textList(h, func(v int) string { return fmt.Sprintf("%02d:00", v) })}, clauses...)
+	}
+	return clauses, nil
+}
+
+func checkTimes(times []string) error {
+	if len(times) > maxTimes {
+		return fmt.Errorf("te syntax would need %d times of day", len(times))
+	}
+	return nil
+}
+
+// textStep writes values that are a progression as a step.
+func textStep(values []int, max int, unit string, offset func(int) string) string {
+	n, off, _ := progression(values, max)
+	s := fmt.Sprintf("every %d %s", n, unit)
+	if off != 0 {
+		s += " starting at " + offset(off)
+	}
+	return s
+}
+
+// textDays returns the clauses for the days and weekdays of a spec.
+func textDays(sp spec) ([]string, error) {
+	if contains(sp.day, -1) {
+		return nil, errors.New("te syntax has no last day of the month")
+	}
+	var clauses []string
+	if sp.weekday != nil {
+		clauses = append(clauses, textList(sp.weekday, func(v int) string {
+			return strings.ToLower(time.Weekday(v).String()[:3])
+		}))
+	}
+	if sp.day != nil {
+		clauses = append(clauses, textList(sp.day, ordinal))
+	}
+	if sp.dayOr && len(clauses) == 2 {
+		clauses = []string{"(" + clauses[1] + " or " + clauses[0] + ")"}
+	}
+	return clauses, nil
+}
+
+func textList(values []int, format func(int) string) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = format(v)
+	}
+	return strings.Join(s, "/")
+}
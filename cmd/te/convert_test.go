@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConvertSchedule(t *testing.T) {
+	tests := []struct {
+		s, from, to string
+		want        string
+	}{
+		{"*/15 9-17 * * 1-5", "cron", "te", "09:00/10:00/11:00/12:00/13:00/14:00/15:00/16:00/17:00 every 15 minutes mon/tue/wed/thu/fri"},
+		{"*/15 9-17 * * 1-5", "cron", "systemd", "Mon..Fri *-*-* 09..17:00/15:00"},
+		{"*/15 9-17 * * 1-5", "cron", "rrule", "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,10,11,12,13,14,15,16,17;BYMINUTE=0,15,30,45;BYSECOND=0"},
+		{"0 0 1,15 * 1", "cron", "te", "(1st/15th or mon)"},
+		{"0 * * * *", "cron", "te", "every hour"},
+		{"0 */2 * * *", "cron", "te", "every 2 hours"},
+		{"30 2 * jan,jul *", "cron", "te", "02:30 jan/jul"},
+		{"0 0 29 2 *", "cron", "te", "29th feb"},
+		{"Mon..Fri *-*-* 09:00:00", "systemd", "cron", "0 9 * * 1-5"},
+		{"minutely", "systemd", "te", "every minute"},
+		{"*-*-* 00:00:30", "systemd", "te", "00:00:30"},
+		{"FREQ=WEEKLY;BYDAY=MO,WE;BYHOUR=9;BYMINUTE=0;BYSECOND=0", "rrule", "te", "09:00 mon/wed"},
+		{"FREQ=HOURLY;BYMINUTE=0;BYSECOND=0", "rrule", "cron", "0 * * * *"},
+		{"every tue at 9am", "te", "cron", "0 9 * * 2"},
+		{"every tue at 9am", "te", "rrule", "FREQ=WEEKLY;BYDAY=TU;BYHOUR=9;BYMINUTE=0;BYSECOND=0"},
+		{"every 15 minutes", "te", "cron", "*/15 * * * *"},
+		{"every 5 minutes starting at :02", "te", "systemd", "*-*-* *:02/5:00"},
+		{"every 10 seconds", "te", "systemd", "*-*-* *:*:00/10"},
+		{"jan 2027", "te", "systemd", "2027-01-01 00:00:00"},
+		{"9am except sat/sun", "te", "cron", "0 9 * * 1-5"},
+		{"9am except sat/sun", "te", "systemd", "Mon..Fri *-*-* 09:00:00"},
+		{"every 15 minutes except dec", "te", "cron", "*/15 * * 1-11 *"},
+		{"9am 1st except jan/jul", "te", "cron", "0 9 1 2-6,8-12 *"},
+	}
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		have, err := convertSchedule(tt.s, tt.from, tt.to, time.UTC, at)
+		if err != nil {
+			t.Errorf("%s from %s to %s: %v", tt.s, tt.from, tt.to, err)
+			continue
+		}
+		if have != tt.want {
+			t.Errorf("%s from %s to %s\nhave %q\nwant %q", tt.s, tt.from, tt.to, have, tt.want)
+		}
+	}
+}
+
+func TestConvertScheduleError(t *testing.T) {
+	tests := []struct {
+		s, from, to string
+		want        string
+	}{
+		{"0 0 1,15 * 1", "cron", "systemd", "cannot convert to systemd: systemd matches both the day of the month and the weekday, not either"},
+		{"*-*~01 12:00", "systemd", "cron", "cannot convert to cron: cron has no last day of the month"},
+		{"*-*~01 12:00", "systemd", "te", "cannot convert to te: te syntax has no last day of the month"},
+		{"every 10 seconds", "te", "cron", "cannot convert to cron: cron has no seconds"},
+		{"FREQ=DAILY;INTERVAL=2", "rrule", "te", "rrule: INTERVAL other than 1 depends on DTSTART and has no equivalent"},
+		{"FREQ=DAILY", "rrule", "cron", "rrule: DAILY without BYSECOND depends on DTSTART"},
+		{"9am except 25th dec", "te", "cron", `cannot convert to cron: except "on the 25th in December" is not a combination of calendar fields`},
+		{"from 10pm to 2am", "te", "cron", `cannot convert to cron: "from 10pm to 2am" is not a combination of calendar fields`},
+		{"9am except 2027", "te", "cron", "cannot convert to cron: cannot exclude years from a schedule in every year"},
+		{"0 0 * * *", "cron", "ical", `invalid syntax "ical", expected te, cron, systemd or rrule`},
+	}
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		_, err := convertSchedule(tt.s, tt.from, tt.to, time.UTC, at)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s from %s to %s\nhave %v\nwant %s", tt.s, tt.from, tt.to, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronSyntax      = syntax{rng: "-", closed: true}
+	cronMonthSyntax = syntax{rng: "-", closed: true, names: monthNames}
+	cronDaySyntax   = syntax{rng: "-", closed: true, names: weekdayNames}
+)
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// parseCron parses a five field crontab schedule or one of its macros,
+// such as @daily. As in cron, a date matches if either its day of the
+// month or its weekday does when both fields are restricted.
+func parseCron(s string) (spec, error) {
+	s = strings.TrimSpace(s)
+	if macro, ok := cronMacros[strings.ToLower(s)]; ok {
+		s = macro
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return spec{}, fmt.Errorf("cron: expected 5 fields, have %d", len(fields))
+	}
+	var sp spec
+	var err error
+	sp.second = []int{0}
+	parse := []struct {
+		sx       syntax
+		min, max int
+		values   *[]int
+	}{
+		{cronSyntax, 0, 59, &sp.minute},
+		{cronSyntax, 0, 23, &sp.hour},
+		{cronSyntax, 1, 31, &sp.day},
+		{cronMonthSyntax, 1, 12, &sp.month},
+		{cronDaySyntax, 0, 7, &sp.weekday},
+	}
+	for i, p := range parse {
+		*p.values, err = p.sx.parseField(fields[i], p.min, p.max)
+		if err != nil {
+			return spec{}, fmt.Errorf("cron: field %d: %v", i+1, err)
+		}
+	}
+	if contains(sp.weekday, 7) {
+		sp.weekday = normalize(append(sp.weekday[:len(sp.weekday)-1], 0))
+	}
+	sp.minute = full(sp.minute, 0, 59)
+	sp.hour = full(sp.hour, 0, 23)
+	sp.day = full(sp.day, 1, 31)
+	sp.month = full(sp.month, 1, 12)
+	sp.weekday = full(sp.weekday, 0, 6)
+	sp.dayOr = !strings.HasPrefix(fields[2], "*") && !strings.HasPrefix(fields[4], "*")
+	return sp, nil
+}
+
+// formatCron formats a spec as a five field crontab schedule.
+func formatCron(sp spec) (string, error) {
+	switch {
+	case sp.second == nil:
+		return "", errors.New("cron runs at most once a minute")
+	case !equal(sp.second, 0):
+		return "", errors.New("cron has no seconds")
+	case sp.year != nil:
+		return "", errors.New("cron has no years")
+	case contains(sp.day, -1):
+		return "", errors.New("cron has no last day of the month")
+	case sp.day != nil && sp.weekday != nil && !sp.dayOr:
+		return "", errors.New("cron matches either the day of the month or the weekday, not both")
+	}
+	// Days written as steps such as */2 would be unrestricted to cron.
+	step := sp.day == nil || sp.weekday == nil
+	return strings.Join([]string{
+		cronSyntax.formatField(sp.minute, 0, 59, true),
+		cronSyntax.formatField(sp.hour, 0, 23, true),
+		cronSyntax.formatField(sp.day, 1, 31, step),
+		cronSyntax.formatField(sp.month, 1, 12, true),
+		cronSyntax.formatField(sp.weekday, 0, 6, false),
+	}, " "), nil
+}
+
+// full returns nil if values are all of [min, max].
+func full(values []int, min, max int) []int {
+	if len(values) == max-min+1 {
+		return nil
+	}
+	return values
+}
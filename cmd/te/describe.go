@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+// describe returns an English description of expr, such as "on Tuesday
+// at 9am or 5pm".
+func describe(expr te.Expression) string {
+	n := te.Inspect(expr)
+	switch n.Kind {
+	case te.KindNil:
+		if n.Err != nil {
+			return "invalid expression: " + n.Err.Error()
+		}
+		return "invalid expression"
+	case te.KindHour:
+		return "at " + clock(n.Args[0].(int), -1, -1)
+	case te.KindHourStep:
+		return step(n.Args, "hour", func(off int) string { return clock(off, -1, -1) })
+	case te.KindMinute:
+		if n.Args[0].(int) == 0 {
+			return "at the start of every hour"
+		}
+		return fmt.Sprintf("at %s past the hour", plural(n.Args[0].(int), "minute"))
+	case te.KindMinuteStep:
+		return step(n.Args, "minute", func(off int) string { return fmt.Sprintf(":%02d", off) })
+	case te.KindSecond:
+		if n.Args[0].(int) == 0 {
+			return "at the start of every minute"
+		}
+		return fmt.Sprintf("at %s past the minute", plural(n.Args[0].(int), "second"))
+	case te.KindSecondStep:
+		return step(n.Args, "second", func(off int) string { return fmt.Sprintf(":%02d seconds", off) })
+	case te.KindMillisecond:
+		return fmt.Sprintf("at %s past the second", plural(n.Args[0].(int), "millisecond"))
+	case te.KindMillisecondly:
+		return step(n.Args, "millisecond", nil)
+	case te.KindMicrosecond:
+		return fmt.Sprintf("at %s past the millisecond", plural(n.Args[0].(int), "microsecond"))
+	case te.KindMicrosecondly:
+		return step(n.Args, "microsecond", nil)
+	case te.KindEvery:
+		d, anchor := n.Args[0].(time.Duration), n.Args[1].(time.Time)
+		return fmt.Sprintf("every %v starting at %s", d, anchor.Format(time.RFC3339))
+	case te.KindDay:
+		day := n.Args[0].(int)
+		if day == -1 {
+			return "on the last day of the month"
+		}
+		return "on the " + ordinal(day)
+	case te.KindDaily:
+		return "every day"
+	case te.KindWeekday:
+		return "on " + n.Args[0].(time.Weekday).String()
+	case te.KindMonth:
+		return "in " + n.Args[0].(time.Month).String()
+	case te.KindYear:
+		return fmt.Sprintf("in %d", n.Args[0].(int))
+	case te.KindDateRange:
+		return fmt.Sprintf("from %s %d to %s %d", n.Args[0], n.Args[1], n.Args[2], n.Args[3])
+	case te.KindTimeRange:
+		a := n.Args
+		return fmt.Sprintf("from %s to %s", clock(a[0].(int), a[1].(int), a[2].(int)), clock(a[3].(int), a[4].(int), a[5].(int)))
+	case te.KindIn:
+		return fmt.Sprintf("%s in %s", describe(n.Children[0]), n.Args[0])
+	case te.KindUnion:
+		return union(n.Children)
+	case te.KindIntersect:
+		return intersect(n.Children)
+	case te.KindExcept:
+		return "except " + union(n.Children)
+	}
+	return fmt.Sprintf("%#v", expr)
+}
+
+// step describes every n units, starting at an offset if there is one.
+func step(args []interface{}, unit string, offset func(int) string) string {
+	s := "every " + unit
+	if n := args[0].(int); n != 1 {
+		s = fmt.Sprintf("every %d %ss", n, unit)
+	}
+	if len(args) > 1 && args[1].(int) != 0 {
+		s += " starting at " + offset(args[1].(int))
+	}
+	return s
+}
+
+// union joins the descriptions of exprs with "or". A preposition shared
+// by all of them is only written once, as in "at 9am or 5pm".
+func union(exprs []te.Expression) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = describe(e)
+	}
+	prefix := preposition(parts[0])
+	for _, p := range parts[1:] {
+		if preposition(p) != prefix {
+			prefix = ""
+		}
+	}
+	if prefix != "" {
+		for i := 1; i < len(parts); i++ {
+			parts[i] = strings.TrimPrefix(parts[i], prefix)
+		}
+	}
+	switch len(parts) {
+	case 1:
+		return parts[0]
+	case 2:
+		return parts[0] + " or " + parts[1]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " or " + parts[len(parts)-1]
+}
+
+// intersect joins the descriptions of exprs. An hour, minute and second
+// are described together as a time of day.
+func intersect(exprs []te.Expression) string {
+	h, m, s := -1, -1, -1
+	var parts []string
+	at := -1
+	for _, e := range exprs {
+		n := te.Inspect(e)
+		switch {
+		case n.Kind == te.KindHour && h < 0:
+			h = n.Args[0].(int)
+		case n.Kind == te.KindMinute && m < 0:
+			m = n.Args[0].(int)
+		case n.Kind == te.KindSecond && s < 0:
+			s = n.Args[0].(int)
+		default:
+			parts = append(parts, describe(e))
+			continue
+		}
+		if at < 0 {
+			at = len(parts)
+			parts = append(parts, "")
+		}
+	}
+	if at >= 0 {
+		switch {
+		case h >= 0:
+			parts[at] = "at " + clock(h, m, s)
+		case m >= 0:
+			parts[at] = describe(te.Minute(m))
+			if s >= 0 {
+				parts[at] = fmt.Sprintf("at %s and %s past the hour", plural(m, "minute"), plural(s, "second"))
+			}
+		default:
+			parts[at] = describe(te.Second(s))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// preposition returns the leading preposition of a description, if any,
+// including the space that follows it.
+func preposition(s string) string {
+	for _, p := range []string{"at ", "on ", "in "} {
+		if strings.HasPrefix(s, p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// clock returns a time of day such as midnight, 3pm, 3:04pm or 3:04:05pm.
+// Negative minutes and seconds are omitted.
+func clock(h, m, s int) string {
+	if m <= 0 && s <= 0 {
+		switch h {
+		case 0:
+			return "midnight"
+		case 12:
+			return "noon"
+		}
+	}
+	suffix := "am"
+	if h >= 12 {
+		suffix = "pm"
+	}
+	h %= 12
+	if h == 0 {
+		h = 12
+	}
+	switch {
+	case s > 0:
+		return fmt.Sprintf("%d:%02d:%02d%s", h, max(m, 0), s, suffix)
+	case m > 0:
+		return fmt.Sprintf("%d:%02d%s", h, m, suffix)
+	}
+	return fmt.Sprintf("%d%s", h, suffix)
+}
+
+// ordinal returns n with its English ordinal suffix, such as 1st or 22nd.
+func ordinal(n int) string {
+	suffix := "th"
+	switch n % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+	if n%100 >= 11 && n%100 <= 13 {
+		suffix = "th"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := map[string]string{
+		"daily at 3pm":                     "at 3pm",
+		"midnight":                         "at midnight",
+		"15:04:05":                         "at 3:04:05pm",
+		"every tuesday at 9am and 5pm":     "on Tuesday at 9am or 5pm",
+		"every 15 minutes starting at :05": "every 15 minutes starting at :05",
+		"every 3 hours starting at 1am":    "every 3 hours starting at 1am",
+		"every 5µs":                        "every 5 microseconds",
+		"every 4th":                        "on the 4th",
+		"hourly":                           "at the start of every hour",
+		"annually at 4am":                  "in January on the 1st at 4am",
+		"Sat/Sun 1am/2am/3am/4am":          "on Saturday or Sunday at 1am, 2am, 3am or 4am",
+		"daily at 9:30 America/New_York":   "at 9:30am in America/New_York",
+		"(3rd or 5th) friday except feb":   "on the 3rd or the 5th on Friday except in February",
+	}
+	for in, want := range tests {
+		expr, err := te.Parse(in, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		have := describe(expr)
+		if have != want {
+			t.Errorf("%s\nhave %q\nwant %q", in, have, want)
+		}
+	}
+}
+
+func TestDescribeExpr(t *testing.T) {
+	tests := map[string]struct {
+		expr te.Expression
+		want string
+	}{
+		"last day":   {te.Day(-1), "on the last day of the month"},
+		"ordinal":    {te.Intersect(te.Day(22), te.Minute(5), te.Second(30)), "on the 22nd at 5 minutes and 30 seconds past the hour"},
+		"time range": {te.TimeRange(9, 0, 0, 17, 30, 0), "from 9am to 5:30pm"},
+		"date range": {te.DateRange(time.December, 24, time.January, 2), "from December 24 to January 2"},
+		"every":      {te.Every(90*time.Minute, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)), "every 1h30m0s starting at 2026-03-01T00:00:00Z"},
+		"invalid":    {te.Hour(25), "invalid expression: te.Hour: hour 25 out of range [0, 23]"},
+	}
+	for name, tt := range tests {
+		have := describe(tt.expr)
+		if have != tt.want {
+			t.Errorf("%s\nhave %q\nwant %q", name, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// position is a line and column of a file, both counting from 1.
+type position struct {
+	line int
+	col  int
+}
+
+// entry is a schedule read from a file.
+type entry struct {
+	name     string
+	expr     string
+	pos      position
+	location string
+	locPos   position
+}
+
+// syntaxError is an error reading the structure of a file.
+type syntaxError struct {
+	pos position
+	msg string
+}
+
+func (e *syntaxError) Error() string {
+	return e.msg
+}
+
+// readEntries reads the schedules of a file in the given format, json,
+// yaml or lines. The empty format is chosen by the name of the file or,
+// failing that, its content.
+func readEntries(name string, data []byte, format string) ([]entry, error) {
+	if format == "" {
+		switch {
+		case strings.HasSuffix(name, ".json"):
+			format = "json"
+		case strings.HasSuffix(name, ".yaml"), strings.HasSuffix(name, ".yml"):
+			format = "yaml"
+		case bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")), bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")):
+			format = "json"
+		default:
+			format = "lines"
+		}
+	}
+	var n *node
+	var err error
+	switch format {
+	case "lines":
+		return readLines(data)
+	case "json":
+		n, err = readJSON(data)
+	case "yaml":
+		n, err = readYAML(data)
+	default:
+		return nil, fmt.Errorf("invalid format %q, expected json, yaml or lines", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []entry
+	n.entries(&entries, "", entry{})
+	return entries, nil
+}
+
+// readLines reads a schedule from each line. Blank lines and lines
+// beginning with # are ignored, and a line such as TZ=Europe/Berlin sets
+// the location of the schedules that follow it, as in a crontab.
+func readLines(data []byte) ([]entry, error) {
+	var entries []entry
+	var loc entry
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimRight(s.Text(), " \t\r")
+		trimmed := strings.TrimLeft(text, " \t")
+		pos := position{line, len(text) - len(trimmed) + 1}
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+		case strings.HasPrefix(trimmed, "TZ="):
+			loc.location = trimmed[len("TZ="):]
+			loc.locPos = position{line, pos.col + len("TZ=")}
+		default:
+			e := loc
+			e.expr, e.pos = trimmed, pos
+			entries = append(entries, e)
+		}
+	}
+	return entries, s.Err()
+}
+
+// node is a value of a JSON or YAML document. A node is a scalar, a
+// mapping if it has keys, or a sequence otherwise.
+type node struct {
+	value  string
+	pos    position
+	scalar bool
+	keys   []string
+	items  []*node
+}
+
+func (n *node) get(keys ...string) *node {
+	for i, k := range n.keys {
+		for _, key := range keys {
+			if k == key && n.items[i].scalar {
+				return n.items[i]
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	scheduleKeys = []string{"schedule", "expr", "expression"}
+	locationKeys = []string{"location", "tz", "timezone"}
+)
+
+// entries appends the schedules of the document rooted at n. A schedule
+// is a string or a mapping with a schedule key and optionally location
+// and name keys. Schedules are found in sequences and as the values of
+// mappings, in which case they are named by their key. A location key
+// of a mapping without a schedule is the location of those within it.
+func (n *node) entries(entries *[]entry, name string, loc entry) {
+	if n.scalar {
+		e := loc
+		e.name, e.expr, e.pos = name, n.value, n.pos
+		*entries = append(*entries, e)
+		return
+	}
+	if l := n.get(locationKeys...); l != nil {
+		loc.location, loc.locPos = l.value, l.pos
+	}
+	if n.keys == nil {
+		for _, item := range n.items {
+			item.entries(entries, name, loc)
+		}
+		return
+	}
+	if s := n.get(scheduleKeys...); s != nil {
+		if v := n.get("name"); v != nil {
+			name = v.value
+		}
+		s.entries(entries, name, loc)
+		return
+	}
+	for i, k := range n.keys {
+		if indexOf(locationKeys, k) >= 0 {
+			continue
+		}
+		n.items[i].entries(entries, k, loc)
+	}
+}
+
+// readJSON reads a JSON document.
+func readJSON(data []byte) (*node, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	n, err := readJSONValue(d, data)
+	if err == nil {
+		_, err = d.Token()
+		if err == nil {
+			err = &syntaxError{offsetPosition(data, int(d.InputOffset())), "unexpected data after top-level value"}
+		} else if err == io.EOF {
+			err = nil
+		}
+	}
+	var e *json.SyntaxError
+	if errors.As(err, &e) {
+		// The offset is after the offending byte, if not at the end.
+		offset := int(e.Offset)
+		if offset < len(data) {
+			offset--
+		}
+		return nil, &syntaxError{offsetPosition(data, offset), e.Error()}
+	}
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		return nil, &syntaxError{offsetPosition(data, len(data)), "unexpected end of JSON input"}
+	}
+	return n, err
+}
+
+func readJSONValue(d *json.Decoder, data []byte) (*node, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	end := int(d.InputOffset())
+	switch t := t.(type) {
+	case json.Delim:
+		n := &node{pos: offsetPosition(data, end-1)}
+		for d.More() {
+			if t == '{' {
+				k, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, k.(string))
+			}
+			item, err := readJSONValue(d, data)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+		}
+		_, err = d.Token()
+		return n, err
+	case string:
+		return &node{value: t, pos: offsetPosition(data, stringStart(data, end)), scalar: true}, nil
+	}
+	return &node{value: fmt.Sprint(t), pos: offsetPosition(data, end), scalar: true}, nil
+}
+
+// stringStart returns the offset of the content of the JSON string that
+// ends at offset end of data.
+func stringStart(data []byte, end int) int {
+	for i := end - 2; i >= 0; i-- {
+		if data[i] != '"' {
+			continue
+		}
+		escapes := 0
+		for j := i - 1; j >= 0 && data[j] == '\\'; j-- {
+			escapes++
+		}
+		if escapes%2 == 0 {
+			return i + 1
+		}
+	}
+	return end
+}
+
+// offsetPosition returns the position of a byte offset of data.
+func offsetPosition(data []byte, offset int) position {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(data) {
+		offset = len(data)
+	}
+	line := bytes.Count(data[:offset], []byte("\n")) + 1
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	return position{line, utf8.RuneCount(data[start:offset]) + 1}
+}
+
+// yamlLine is a line of a YAML document without its indentation and
+// comment.
+type yamlLine struct {
+	line   int
+	indent int
+	text   string
+}
+
+// readYAML reads the subset of YAML used by configuration files: block
+// mappings and sequences of plain, single quoted and double quoted
+// scalars. Flow collections, anchors, aliases, tags and block scalars
+// are reported as syntax errors.
+func readYAML(data []byte) (*node, error) {
+	var lines []yamlLine
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; s.Scan(); line++ {
+		text := stripComment(s.Text())
+		trimmed := strings.TrimLeft(text, " ")
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, &syntaxError{position{line, len(text) - len(trimmed) + 1}, "tabs are not allowed for indentation"}
+		}
+		if trimmed == "" || trimmed == "---" || trimmed == "..." {
+			continue
+		}
+		lines = append(lines, yamlLine{line, len(text) - len(trimmed), trimmed})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return &node{}, nil
+	}
+	p := yamlParser{lines: lines}
+	n, err := p.block(lines[0].indent)
+	if err == nil && p.i < len(lines) {
+		l := lines[p.i]
+		err = &syntaxError{position{l.line, l.indent + 1}, "unexpected indentation"}
+	}
+	return n, err
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+// block parses the mapping or sequence whose lines are at indent.
+func (p *yamlParser) block(indent int) (*node, error) {
+	l := p.lines[p.i]
+	n := &node{pos: position{l.line, l.indent + 1}}
+	seq := isSeqItem(l.text)
+	for p.i < len(p.lines) {
+		l := p.lines[p.i]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent || isSeqItem(l.text) != seq {
+			return nil, &syntaxError{position{l.line, l.indent + 1}, "unexpected indentation"}
+		}
+		if seq {
+			item, err := p.seqItem(l)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+			continue
+		}
+		k, v, err := splitKey(l)
+		if err != nil {
+			return nil, err
+		}
+		p.i++
+		item := v
+		if v == nil {
+			item, err = p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+		}
+		n.keys = append(n.keys, k)
+		n.items = append(n.items, item)
+	}
+	return n, nil
+}
+
+// seqItem parses the sequence item beginning on line l.
+func (p *yamlParser) seqItem(l yamlLine) (*node, error) {
+	content := strings.TrimLeft(l.text[1:], " ")
+	if content == "" {
+		p.i++
+		return p.nested(l.indent)
+	}
+	indent := l.indent + len(l.text) - len(content)
+	if _, _, err := splitKey(yamlLine{l.line, indent, content}); err == nil {
+		// A mapping begins on the line of the item, continuing on the
+		// lines indented as far as its first key.
+		p.lines[p.i] = yamlLine{l.line, indent, content}
+		return p.block(indent)
+	}
+	p.i++
+	return scalar(content, position{l.line, indent + 1})
+}
+
+// nested parses the value on the lines following a key or item with no
+// value on its own line. A sequence may be at the same indentation as
+// the key of a mapping.
+func (p *yamlParser) nested(indent int) (*node, error) {
+	if p.i < len(p.lines) {
+		l := p.lines[p.i]
+		if l.indent > indent || (l.indent == indent && isSeqItem(l.text) && !isSeqItem(p.lines[p.i-1].text)) {
+			return p.block(l.indent)
+		}
+	}
+	prev := p.lines[p.i-1]
+	return &node{pos: position{prev.line, prev.indent + len(prev.text) + 1}, scalar: true}, nil
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitKey splits a mapping line into its key and value, if any.
+func splitKey(l yamlLine) (string, *node, error) {
+	i := 0
+	if c := l.text[0]; c == '"' || c == '\'' {
+		i = strings.IndexByte(l.text[1:], c) + 2
+	}
+	j := strings.Index(l.text[i:], ": ")
+	if j < 0 && strings.HasSuffix(l.text, ":") {
+		j = len(l.text) - 1 - i
+	}
+	// An unterminated quote leaves i at 1.
+	if i == 1 || j < 0 || i+j == 0 {
+		return "", nil, &syntaxError{position{l.line, l.indent + 1}, "expected a key followed by a colon"}
+	}
+	i += j
+	key := strings.TrimSpace(l.text[:i])
+	if msg := unsupported(key); msg != "" {
+		return "", nil, &syntaxError{position{l.line, l.indent + 1}, msg}
+	}
+	k := unquote(key)
+	rest := l.text[i+1:]
+	v := strings.TrimLeft(rest, " ")
+	if v == "" {
+		return k, nil, nil
+	}
+	col := l.indent + i + 1 + len(rest) - len(v) + 1
+	n, err := scalar(v, position{l.line, col})
+	return k, n, err
+}
+
+// scalar returns the node of a plain or quoted scalar at pos.
+func scalar(s string, pos position) (*node, error) {
+	if msg := unsupported(s); msg != "" {
+		return nil, &syntaxError{pos, msg}
+	}
+	if isQuoted(s) {
+		pos.col++
+	}
+	return &node{value: unquote(s), pos: pos, scalar: true}, nil
+}
+
+// unsupported describes the YAML construct beginning s that readYAML
+// does not read, or returns the empty string if there is none.
+func unsupported(s string) string {
+	switch s[0] {
+	case '{', '[':
+		return "flow collections are not supported, use block mappings and sequences"
+	case '&', '*':
+		return "anchors and aliases are not supported"
+	case '!':
+		return "tags are not supported"
+	case '|', '>':
+		return "block scalars are not supported, write the schedule on one line"
+	case '"', '\'':
+		if !isQuoted(s) {
+			return "unterminated quoted scalar"
+		}
+	case '?', '%', '@', '`':
+		return fmt.Sprintf("unexpected character %q", s[0])
+	}
+	return ""
+}
+
+func isQuoted(s string) bool {
+	return len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0]
+}
+
+func unquote(s string) string {
+	if !isQuoted(s) {
+		return s
+	}
+	if s[0] == '\'' {
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1)
+	}
+	v, err := strconv.Unquote(s)
+	if err != nil {
+		return s[1 : len(s)-1]
+	}
+	return v
+}
+
+// stripComment removes a comment and trailing space from a YAML line.
+// A comment begins with a # at the start of the line or after a space,
+// outside of quotes.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.IndexByte(" :-[{,", s[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return strings.TrimRight(s[:i], " \t\r")
+		}
+	}
+	return strings.TrimRight(s, " \t\r")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pnelson/te"
+	"github.com/pnelson/te/internal/tokens"
+)
+
+// explain prints how an expression is read and parsed, followed by its
+// next occurrences.
+func explain(args []string) error {
+	var o options
+	fs := newFlagSet("te explain", "[OPTIONS] EXPR")
+	o.register(fs)
+	n := fs.Int("n", 5, "number of occurrences")
+	f := fs.String("f", "Mon Jan 2 2006 15:04 MST", "time format layout")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	text := strings.Join(fs.Args(), " ")
+	ts, err := tokens.Lex(text)
+	if err != nil {
+		return err
+	}
+	writeTokens(os.Stdout, ts)
+	e, _, t, err := o.parse(fs.Args())
+	if err != nil {
+		return err
+	}
+	fmt.Println("\nTree:")
+	writeTree(os.Stdout, e, 1)
+	fmt.Printf("\nGo:\n  %#v\n", e)
+	fmt.Printf("\nDescription:\n  %s\n", describe(e))
+	fmt.Println("\nNext:")
+	for i := 0; i < *n; i++ {
+		t = e.Next(t)
+		if t.IsZero() {
+			fmt.Println("  never")
+			break
+		}
+		fmt.Printf("  %s\n", t.Format(*f))
+	}
+	return nil
+}
+
+// writeTokens writes a token per line with its offset and type.
+func writeTokens(w io.Writer, ts []tokens.Token) {
+	fmt.Fprintln(w, "Tokens:")
+	width := 0
+	for _, t := range ts {
+		width = max(width, len(t.Type))
+	}
+	for _, t := range ts {
+		fmt.Fprintf(w, "  %3d  %-*s  %q\n", t.Offset, width, t.Type, t.Value)
+	}
+}
+
+// writeTree writes expr as an outline indented by depth, with a line for
+// each expression and its arguments.
+func writeTree(w io.Writer, expr te.Expression, depth int) {
+	n := te.Inspect(expr)
+	line := n.Kind.String()
+	switch n.Kind {
+	case te.KindNil:
+		if n.Err != nil {
+			line += ": " + n.Err.Error()
+		}
+	case te.KindOther:
+		line += fmt.Sprintf(" %#v", expr)
+	}
+	if len(n.Args) > 0 {
+		args := make([]string, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = formatArg(arg)
+		}
+		line += "(" + strings.Join(args, ", ") + ")"
+	}
+	if n.Policy != (te.Policy{}) {
+		line += fmt.Sprintf(" gap=%v overlap=%v", n.Policy.Gap, n.Policy.Overlap)
+	}
+	fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth), line)
+	for _, child := range n.Children {
+		writeTree(w, child, depth+1)
+	}
+}
+
+func formatArg(arg interface{}) string {
+	switch v := arg.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case *time.Location:
+		return v.String()
+	}
+	return fmt.Sprint(arg)
+}
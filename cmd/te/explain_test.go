@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pnelson/te"
+	"github.com/pnelson/te/internal/tokens"
+)
+
+func TestWriteTokens(t *testing.T) {
+	ts, err := tokens.Lex("annually at 4am")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	writeTokens(&buf, ts)
+	want := `Tokens:
+    0  yearly    "annually"
+    9  at        "at"
+   12  digit     "4"
+   13  am or pm  "am"
+`
+	if buf.String() != want {
+		t.Errorf("have\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteTree(t *testing.T) {
+	expr := te.Union(
+		te.Intersect(te.Weekday(time.Tuesday), te.In(te.Hour(9), time.UTC)),
+		te.WithPolicy(te.Hour(2), te.Policy{Gap: te.GapSkip}),
+		te.Hour(25),
+	)
+	var buf bytes.Buffer
+	writeTree(&buf, expr, 0)
+	want := `Union
+  Intersect
+    Weekday(Tuesday)
+    In(UTC)
+      Hour(9)
+  Hour(2) gap=skip overlap=first
+  Nil: te.Hour: hour 25 out of range [0, 23]
+`
+	if buf.String() != want {
+		t.Errorf("have\n%s\nwant\n%s", buf.String(), want)
+	}
+}
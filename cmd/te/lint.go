@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+// lintWindow is the period after the reference time that is checked for
+// daylight saving time transitions, and lintCount the number of
+// occurrences checked for their interval.
+const (
+	lintWindow = 366 * 24 * time.Hour
+	lintCount  = 20
+)
+
+// lint checks a file of schedules, reporting errors and suspicious
+// schedules. It exits with status 1 if any problem is found.
+func lint(args []string) error {
+	var o options
+	fs := newFlagSet("te lint", "[OPTIONS] FILE")
+	o.register(fs)
+	format := fs.String("format", "", "file format: json, yaml or lines (default by file name)")
+	interval := fs.Duration("min-interval", time.Minute, "warn about schedules occurring more often than this")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError(2)
+	}
+	name := fs.Arg(0)
+	var data []byte
+	if name == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(name)
+	}
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(o.l)
+	if err != nil {
+		return err
+	}
+	t, err := parseTime(o.from, time.Now(), loc)
+	if err != nil {
+		return err
+	}
+	l := newLinter(loc, t, *interval)
+	if l.file(os.Stdout, name, data, *format) > 0 {
+		return exitError(1)
+	}
+	return nil
+}
+
+// problem is an error or warning about a schedule.
+type problem struct {
+	pos     position
+	warning bool
+	msg     string
+}
+
+// linter checks the schedules of files.
+type linter struct {
+	loc         *time.Location // location of schedules without one
+	t           time.Time      // reference time
+	minInterval time.Duration
+	seen        map[string]entry
+	transitions map[string][]transition
+}
+
+func newLinter(loc *time.Location, t time.Time, minInterval time.Duration) *linter {
+	return &linter{
+		loc:         loc,
+		t:           t,
+		minInterval: minInterval,
+		seen:        make(map[string]entry),
+		transitions: make(map[string][]transition),
+	}
+}
+
+// file checks the schedules of a file, writing each problem to w as
+// FILE:LINE:COLUMN: error: MESSAGE. It returns the number of problems.
+func (l *linter) file(w io.Writer, name string, data []byte, format string) int {
+	entries, err := readEntries(name, data, format)
+	if err != nil {
+		pos := position{1, 1}
+		var e *syntaxError
+		if errors.As(err, &e) {
+			pos = e.pos
+		}
+		fmt.Fprintf(w, "%s:%d:%d: error: %v\n", name, pos.line, pos.col, err)
+		return 1
+	}
+	n := 0
+	for _, e := range entries {
+		for _, p := range l.check(e) {
+			severity := "error"
+			if p.warning {
+				severity = "warning"
+			}
+			msg := p.msg
+			if e.name != "" {
+				msg = e.name + ": " + msg
+			}
+			fmt.Fprintf(w, "%s:%d:%d: %s: %s\n", name, p.pos.line, p.pos.col, severity, msg)
+			n++
+		}
+	}
+	return n
+}
+
+// check returns the problems of a schedule.
+func (l *linter) check(e entry) []problem {
+	loc := l.loc
+	if e.location != "" {
+		var err error
+		loc, err = time.LoadLocation(e.location)
+		if err != nil {
+			return []problem{{e.locPos, false, fmt.Sprintf("invalid location %q", e.location)}}
+		}
+	}
+	expr, err := te.Parse(e.expr, loc)
+	if err != nil {
+		pos := e.pos
+		msg := err.Error()
+		var pe *te.ParseError
+		if errors.As(err, &pe) {
+			pos.col += pe.Column - 1
+			msg = strings.TrimPrefix(msg, fmt.Sprintf("column %d: ", pe.Column))
+		}
+		return []problem{{pos, false, msg}}
+	}
+	warn := func(format string, args ...interface{}) []problem {
+		return []problem{{e.pos, true, fmt.Sprintf(format, args...)}}
+	}
+	key := loc.String() + "\x00" + fmt.Sprintf("%#v", expr)
+	if prev, ok := l.seen[key]; ok {
+		return warn("same schedule as line %d", prev.pos.line)
+	}
+	l.seen[key] = e
+	t := l.t.In(loc)
+	next := expr.Next(t)
+	if next.IsZero() {
+		return warn("never occurs after %s", t.Format("Mon Jan 2 2006 15:04 MST"))
+	}
+	var problems []problem
+	if d := minInterval(expr, next); d > 0 && d < l.minInterval {
+		problems = append(problems, warn("occurs %v apart, more often than every %v", d, l.minInterval)...)
+	}
+	if msg := l.ambiguous(expr, loc, t); msg != "" {
+		problems = append(problems, warn("%s", msg)...)
+	}
+	return problems
+}
+
+// minInterval returns the shortest interval between the first
+// occurrences of expr from next, or zero if it occurs only once.
+func minInterval(expr te.Expression, next time.Time) time.Duration {
+	var rv time.Duration
+	for i := 1; i < lintCount; i++ {
+		t := expr.Next(next)
+		if t.IsZero() {
+			break
+		}
+		if d := t.Sub(next); rv == 0 || d < rv {
+			rv = d
+		}
+		next = t
+	}
+	return rv
+}
+
+// transition is a change of the offset of a location.
+type transition struct {
+	at     time.Time
+	before int // offset in seconds east of UTC
+	after  int
+}
+
+// transitionsOf returns the transitions of loc in the lint window after t.
+func (l *linter) transitionsOf(loc *time.Location, t time.Time) []transition {
+	if rv, ok := l.transitions[loc.String()]; ok {
+		return rv
+	}
+	var rv []transition
+	from := t.Truncate(time.Hour).Unix()
+	_, offset := t.In(loc).Zone()
+	for s := from; s < from+int64(lintWindow/time.Second); s += 3600 {
+		_, next := time.Unix(s+3600, 0).In(loc).Zone()
+		if next == offset {
+			continue
+		}
+		lo, hi := s, s+3600
+		for hi-lo > 1 {
+			mid := lo + (hi-lo)/2
+			if _, o := time.Unix(mid, 0).In(loc).Zone(); o == offset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		rv = append(rv, transition{time.Unix(hi, 0), offset, next})
+		offset = next
+	}
+	l.transitions[loc.String()] = rv
+	return rv
+}
+
+// ambiguous describes the first occurrence of a schedule at a wall clock
+// time that is skipped or repeated by a daylight saving time transition
+// of loc, or returns the empty string if there is none. Schedules that
+// also occur in the period before such times, such as every minute, are
+// not specific to them and are not reported.
+func (l *linter) ambiguous(expr te.Expression, loc *time.Location, t time.Time) string {
+	for _, tr := range l.transitionsOf(loc, t) {
+		// In a fixed zone at the lesser offset, the wall clock times
+		// skipped or repeated by the transition are those from the
+		// transition for the difference of the offsets.
+		offset, d := tr.before, tr.after-tr.before
+		if d < 0 {
+			offset, d = tr.after, -d
+		}
+		dur := time.Duration(d) * time.Second
+		from := tr.at.In(time.FixedZone(loc.String(), offset))
+		at, ok := occursIn(expr, from, from.Add(dur))
+		if !ok {
+			continue
+		}
+		if _, ok := occursIn(expr, from.Add(-dur), from); ok {
+			continue
+		}
+		what := "does not exist"
+		if tr.after < tr.before {
+			what = "occurs twice"
+		}
+		return fmt.Sprintf("occurs at %s on %s, which %s in %s", at.Format("15:04"), at.Format("Mon Jan 2 2006"), what, loc)
+	}
+	return ""
+}
+
+// occursIn returns the first occurrence of expr in [from, to), if any.
+func occursIn(expr te.Expression, from, to time.Time) (time.Time, bool) {
+	t := expr.Next(from.Add(-time.Nanosecond))
+	return t, !t.IsZero() && t.Before(to)
+}
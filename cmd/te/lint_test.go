@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadEntries(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want []entry
+	}{
+		"schedules.yaml": {
+			`# jobs
+location: Europe/Berlin
+backup:
+  schedule: "every day at 02:30"  # nightly
+report: every mon at 09:00
+regions:
+  - every tue
+  - schedule: 'every wed'
+    tz: Asia/Tokyo
+`,
+			[]entry{
+				{"backup", "every day at 02:30", position{4, 14}, "Europe/Berlin", position{2, 11}},
+				{"report", "every mon at 09:00", position{5, 9}, "Europe/Berlin", position{2, 11}},
+				{"regions", "every tue", position{7, 5}, "Europe/Berlin", position{2, 11}},
+				{"regions", "every wed", position{8, 16}, "Asia/Tokyo", position{9, 9}},
+			},
+		},
+		"schedules.json": {
+			`[
+  "every day",
+  {"name": "x", "schedule": "every \"tue\"", "location": "UTC"}
+]`,
+			[]entry{
+				{"", "every day", position{2, 4}, "", position{}},
+				{"x", `every "tue"`, position{3, 30}, "UTC", position{3, 59}},
+			},
+		},
+		"crontab": {
+			"every day\n\n# comment\nTZ=Asia/Tokyo\n  every tue\n",
+			[]entry{
+				{"", "every day", position{1, 1}, "", position{}},
+				{"", "every tue", position{5, 3}, "Asia/Tokyo", position{4, 4}},
+			},
+		},
+	}
+	for name, tt := range tests {
+		have, err := readEntries(name, []byte(tt.data), "")
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("%s\nhave %v\nwant %v", name, have, tt.want)
+		}
+	}
+}
+
+func TestReadEntriesError(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		pos  position
+	}{
+		"truncated.json": {"[\n  \"every day\",\n", position{3, 1}},
+		"invalid.json":   {"[\n  every day\n]", position{2, 3}},
+		"tabs.yaml":      {"a:\n\tb: every day\n", position{2, 1}},
+		"indented.yaml":  {"a: every day\n  b: every tue\n", position{2, 3}},
+		"mapping.yaml":   {"report: {schedule: \"mon at 9am\"}\n", position{1, 9}},
+		"flow.yaml":      {"multi: [\"hourly\", \"daily\"]\n", position{1, 8}},
+		"item.yaml":      {"multi:\n  - [hourly]\n", position{2, 5}},
+		"key.yaml":       {"{a: b}: every day\n", position{1, 1}},
+		"anchor.yaml":    {"a: &x every day\nb: *x\n", position{1, 4}},
+		"block.yaml":     {"a: |\n  every day\n", position{1, 4}},
+		"quote.yaml":     {"a: \"every day\n", position{1, 4}},
+	}
+	for name, tt := range tests {
+		_, err := readEntries(name, []byte(tt.data), "")
+		e, ok := err.(*syntaxError)
+		if !ok || e.pos != tt.pos {
+			t.Errorf("%s\nhave %v %v\nwant error at %v", name, err, e, tt.pos)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	data := `location: America/New_York
+backup: every day at 02:30
+fall: every day at 01:30
+hourly: every hour
+report: every mon at 09:00
+copy: every monday at 09:00
+poll: every 10 seconds
+old: 2020
+never: mon tue
+typo: every wendesday
+berlin:
+  schedule: every day
+  location: Europe/Nowhere
+`
+	want := `lint.yaml:2:9: warning: backup: occurs at 02:30 on Sun Mar 8 2026, which does not exist in America/New_York
+lint.yaml:3:7: warning: fall: occurs at 01:30 on Sun Nov 1 2026, which occurs twice in America/New_York
+lint.yaml:6:7: warning: copy: same schedule as line 5
+lint.yaml:7:7: warning: poll: occurs 10s apart, more often than every 1m0s
+lint.yaml:8:6: warning: old: never occurs after Thu Jan 1 2026 00:00 EST
+lint.yaml:9:8: warning: never: never occurs after Thu Jan 1 2026 00:00 EST
+lint.yaml:10:13: error: typo: unknown word, token: "wendesday", did you mean "wednesday"?
+lint.yaml:13:13: error: berlin: invalid location "Europe/Nowhere"
+`
+	var buf bytes.Buffer
+	l := newLinter(time.UTC, time.Date(2026, 1, 1, 5, 0, 0, 0, time.UTC), time.Minute)
+	n := l.file(&buf, "lint.yaml", []byte(data), "")
+	if buf.String() != want || n != 8 {
+		t.Errorf("have %d\n%s\nwant 8\n%s", n, buf.String(), want)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// list prints the occurrences of an expression.
+func list(args []string) error {
+	var o options
+	fs := newFlagSet("te", "[COMMAND] [OPTIONS] EXPR")
+	o.register(fs)
+	help := fs.Bool("h", false, "show this usage information")
+	u := fs.Bool("u", false, "output as UTC, or add a UTC column to -show-zones")
+	s := fs.Bool("s", false, "output as Unix time in seconds")
+	f := fs.String("f", "Mon Jan 2 15:04 MST", "time format layout")
+	n := fs.Int("n", 1, "number of time generations")
+	until := fs.String("until", "", "stop at this time")
+	prevs := fs.Bool("prev", false, "generate previous times instead of next times")
+	rfc3339 := fs.Bool("rfc-3339", false, "output as RFC 3339 format")
+	format := fs.String("o", "", "output as json, csv or ndjson")
+	zones := fs.String("show-zones", "", "output as a table with a column per comma separated location")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: te [COMMAND] [OPTIONS] EXPR\n\nCommands:\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+		}
+		fmt.Fprintf(os.Stderr, "\nWithout a command, the next times of EXPR are printed.\n\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nTimes may be now, today, yesterday, tomorrow, RFC 3339, Unix seconds,\n")
+		fmt.Fprintf(os.Stderr, "a duration relative to now such as -72h, or a date such as 2026-03-29 15:04.\n")
+	}
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *help {
+		fs.Usage()
+		return nil
+	}
+	e, loc, t, err := o.parse(fs.Args())
+	if err != nil {
+		return err
+	}
+	var end time.Time
+	count := *n
+	if *until != "" {
+		end, err = parseTime(*until, time.Now(), loc)
+		if err != nil {
+			return err
+		}
+		if !isSet(fs, "n") {
+			count = -1 // until the end
+		}
+	}
+	if *rfc3339 {
+		*f = time.RFC3339
+	}
+	var out *output
+	var table *zoneTable
+	switch {
+	case *zones != "" && (*format != "" || *s):
+		return errors.New("-show-zones cannot be used with -o or -s")
+	case *zones != "":
+		table, err = newZoneTable(os.Stdout, *zones, *u, *f)
+		if err != nil {
+			return err
+		}
+	case *format != "":
+		out, err = newOutput(os.Stdout, *format, newHeader(strings.Join(fs.Args(), " "), e, loc))
+		if err != nil {
+			return err
+		}
+	}
+	for i := 0; count < 0 || i < count; i++ {
+		if *prevs {
+			t = prev(e, t)
+		} else {
+			t = e.Next(t)
+		}
+		if t.IsZero() {
+			break
+		}
+		if !end.IsZero() && (*prevs && t.Before(end) || !*prevs && t.After(end)) {
+			break
+		}
+		if out != nil {
+			err = out.write(t)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if table != nil {
+			err = table.write(t)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if *s {
+			fmt.Println(t.Unix())
+			continue
+		}
+		next := t
+		if *u {
+			next = next.In(time.UTC)
+		}
+		fmt.Println(next.Format(*f))
+	}
+	if out != nil {
+		return out.close()
+	}
+	if table != nil {
+		return table.flush()
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -10,58 +11,115 @@ import (
 	"github.com/pnelson/te"
 )
 
-var (
-	help = flag.Bool("h", false, "show this usage information")
-
-	u = flag.Bool("u", false, "output as UTC")
-	s = flag.Bool("s", false, "output as Unix time in seconds")
-	f = flag.String("f", "Mon Jan 2 15:04 MST", "time format layout")
-	l = flag.String("l", "Local", "expression timezone location")
-	n = flag.Int("n", 1, "number of time generations")
+// command is a subcommand of te. The default command lists occurrences.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string) error
+}
 
-	rfc3339 = flag.Bool("rfc-3339", false, "output as RFC 3339 format")
-)
+var commands []command
 
 func init() {
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS] EXPR\n\n", os.Args[0])
-		flag.PrintDefaults()
+	commands = []command{
+		{"cal", "[OPTIONS] EXPR", "show a calendar of the days an expression is active", cal},
+		{"active", "[OPTIONS] EXPR", "exit with status 0 if an expression is active, 1 if not", active},
+		{"explain", "[OPTIONS] EXPR", "show how an expression is parsed", explain},
+		{"convert", "-from SYNTAX -to SYNTAX [OPTIONS] SCHEDULE", "convert a schedule between te, cron, systemd and rrule", convert},
+		{"lint", "[OPTIONS] FILE", "check a file of schedules for errors and suspicious schedules", lint},
+		{"repl", "[OPTIONS]", "evaluate expressions interactively", repl},
+		{"wait", "[OPTIONS] EXPR", "wait until the next occurrence of an expression", wait},
+		{"run", "[OPTIONS] EXPR -- COMMAND [ARGS]", "run a command on each occurrence of an expression", run},
 	}
 }
 
+// exitError is returned by commands to exit with a status other than 1.
+type exitError int
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
 func main() {
-	flag.Parse()
-	if *help {
-		flag.Usage()
+	args := os.Args[1:]
+	run := list
+	if len(args) > 0 {
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				run, args = cmd.run, args[1:]
+				break
+			}
+		}
+	}
+	err := run(args)
+	if err == nil || err == flag.ErrHelp {
 		return
 	}
-	loc, err := time.LoadLocation(*l)
+	var code exitError
+	if errors.As(err, &code) {
+		os.Exit(int(code))
+	}
+	fmt.Fprintf(os.Stderr, "%v\n", err)
+	os.Exit(1)
+}
+
+// options are the flags shared by commands that evaluate an expression.
+type options struct {
+	l    string
+	from string
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.l, "l", "Local", "expression timezone location")
+	fs.StringVar(&o.from, "t", "now", "reference time to generate from")
+	fs.StringVar(&o.from, "from", "now", "reference time to generate from")
+}
+
+// parse returns the expression in args, its location and the reference time.
+func (o *options) parse(args []string) (te.Expression, *time.Location, time.Time, error) {
+	loc, err := time.LoadLocation(o.l)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	t, err := parseTime(o.from, time.Now(), loc)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
-		return
+		return nil, nil, time.Time{}, err
 	}
-	args := flag.Args()
-	expr := strings.Join(args, " ")
-	e, err := te.Parse(expr, loc)
+	e, err := te.Parse(strings.Join(args, " "), loc)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
-		return
+		return nil, nil, time.Time{}, err
+	}
+	return e, loc, t, nil
+}
+
+// newFlagSet returns a flag set for the named command.
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s\n\n", name, args)
+		fs.PrintDefaults()
 	}
-	if *rfc3339 {
-		*f = time.RFC3339
+	return fs
+}
+
+// parseFlags parses args into fs. The flag package has already reported
+// any error, so it is returned as an exit status of 2.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil && err != flag.ErrHelp {
+		return exitError(2)
 	}
-	next := time.Now()
-	for i := 0; i < *n; i++ {
-		next = e.Next(next)
-		if *s {
-			fmt.Println(next.Unix())
-			continue
-		}
-		if *u {
-			next = next.In(time.UTC)
+	return err
+}
+
+// isSet reports whether the named flag was set on the command line.
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
 		}
-		fmt.Println(next.Format(*f))
-	}
+	})
+	return set
 }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+// header describes the expression whose occurrences are written.
+type header struct {
+	Expr        string `json:"expr"`
+	Description string `json:"description"`
+	GoString    string `json:"goString"`
+	Location    string `json:"location"`
+}
+
+func newHeader(text string, expr te.Expression, loc *time.Location) header {
+	return header{
+		Expr:        text,
+		Description: describe(expr),
+		GoString:    fmt.Sprintf("%#v", expr),
+		Location:    loc.String(),
+	}
+}
+
+// occurrence is a time of an expression. Delta is the number of seconds
+// since the previous occurrence, or nil for the first occurrence.
+type occurrence struct {
+	Time    string   `json:"time"`
+	UTC     string   `json:"utc"`
+	Unix    int64    `json:"unix"`
+	Weekday string   `json:"weekday"`
+	Delta   *float64 `json:"delta"`
+}
+
+var occurrenceColumns = []string{"time", "utc", "unix", "weekday", "delta"}
+
+// output writes occurrences in a machine-readable format:
+//
+//	json    an object with the header fields and an array of occurrences
+//	ndjson  the header followed by an occurrence per line
+//	csv     a row of column names followed by an occurrence per row
+type output struct {
+	w      io.Writer
+	format string
+	header header
+	csv    *csv.Writer
+	occs   []occurrence
+	prev   time.Time
+}
+
+func newOutput(w io.Writer, format string, h header) (*output, error) {
+	o := &output{w: w, format: format, header: h, occs: make([]occurrence, 0)}
+	switch format {
+	case "json":
+	case "ndjson":
+		return o, json.NewEncoder(w).Encode(h)
+	case "csv":
+		o.csv = csv.NewWriter(w)
+		return o, o.csv.Write(occurrenceColumns)
+	default:
+		return nil, fmt.Errorf("invalid output format %q, expected json, csv or ndjson", format)
+	}
+	return o, nil
+}
+
+// write writes the occurrence at t.
+func (o *output) write(t time.Time) error {
+	occ := occurrence{
+		Time:    t.Format(time.RFC3339Nano),
+		UTC:     t.UTC().Format(time.RFC3339Nano),
+		Unix:    t.Unix(),
+		Weekday: t.Weekday().String(),
+	}
+	if !o.prev.IsZero() {
+		delta := t.Sub(o.prev).Seconds()
+		occ.Delta = &delta
+	}
+	o.prev = t
+	switch o.format {
+	case "ndjson":
+		return json.NewEncoder(o.w).Encode(occ)
+	case "csv":
+		var delta string
+		if occ.Delta != nil {
+			delta = strconv.FormatFloat(*occ.Delta, 'f', -1, 64)
+		}
+		return o.csv.Write([]string{occ.Time, occ.UTC, strconv.FormatInt(occ.Unix, 10), occ.Weekday, delta})
+	}
+	o.occs = append(o.occs, occ)
+	return nil
+}
+
+// close writes any buffered output.
+func (o *output) close() error {
+	switch o.format {
+	case "json":
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			header
+			Occurrences []occurrence `json:"occurrences"`
+		}{o.header, o.occs})
+	case "csv":
+		o.csv.Flush()
+		return o.csv.Error()
+	}
+	return nil
+}
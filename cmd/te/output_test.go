@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+func TestOutput(t *testing.T) {
+	expr := te.Hour(9)
+	times := []time.Time{
+		time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+	}
+	tests := map[string]string{
+		"ndjson": `{"expr":"at 9am","description":"at 9am","goString":"te.Hour(9)","location":"UTC"}
+{"time":"2026-03-01T09:00:00Z","utc":"2026-03-01T09:00:00Z","unix":1772355600,"weekday":"Sunday","delta":null}
+{"time":"2026-03-02T09:00:00Z","utc":"2026-03-02T09:00:00Z","unix":1772442000,"weekday":"Monday","delta":86400}
+`,
+		"csv": `time,utc,unix,weekday,delta
+2026-03-01T09:00:00Z,2026-03-01T09:00:00Z,1772355600,Sunday,
+2026-03-02T09:00:00Z,2026-03-02T09:00:00Z,1772442000,Monday,86400
+`,
+	}
+	for format, want := range tests {
+		var buf bytes.Buffer
+		out, err := newOutput(&buf, format, newHeader("at 9am", expr, time.UTC))
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		for _, tm := range times {
+			err = out.write(tm)
+			if err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+		}
+		err = out.close()
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if buf.String() != want {
+			t.Errorf("%s\nhave\n%s\nwant\n%s", format, buf.String(), want)
+		}
+	}
+}
+
+func TestOutputJSON(t *testing.T) {
+	var buf bytes.Buffer
+	out, err := newOutput(&buf, "json", newHeader("at 9am", te.Hour(9), time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = out.close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &have)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have["goString"] != "te.Hour(9)" || have["occurrences"] == nil {
+		t.Errorf("have %v", have)
+	}
+}
+
+func TestOutputInvalid(t *testing.T) {
+	_, err := newOutput(&bytes.Buffer{}, "xml", header{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pnelson/te"
+)
+
+const replHelp = `Type an expression to see its description and next occurrences.
+
+  :tz [LOCATION]  show or set the location, such as Europe/Berlin
+  :at [TIME]      show or set the reference time, such as 2026-03-29T00:00
+  :n [COUNT]      show or set the number of occurrences
+  :history        list previous expressions
+  !N, !!          evaluate expression N of the history, or the last one
+  :help           show this help
+  :quit           exit, as does end of input
+`
+
+// repl reads expressions interactively.
+func repl(args []string) error {
+	var o options
+	fs := newFlagSet("te repl", "[OPTIONS]")
+	o.register(fs)
+	n := fs.Int("n", 5, "number of occurrences")
+	f := fs.String("f", "Mon Jan 2 2006 15:04 MST", "time format layout")
+	history := fs.String("history", defaultHistory(), "history file, or empty to disable")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(o.l)
+	if err != nil {
+		return err
+	}
+	_, err = parseTime(o.from, time.Now(), loc)
+	if err != nil {
+		return err
+	}
+	r := &session{out: os.Stdout, loc: loc, at: o.from, n: *n, layout: *f, prompt: "te> "}
+	if *history != "" {
+		r.history = loadHistory(*history)
+		file, err := os.OpenFile(*history, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err == nil {
+			defer file.Close()
+			r.save = file
+		}
+	}
+	fmt.Fprintln(r.out, "Type :help for help.")
+	r.run(os.Stdin)
+	return nil
+}
+
+// session is the state of an interactive session.
+type session struct {
+	out     io.Writer
+	loc     *time.Location
+	at      string // reference time, parsed per evaluation so that now advances
+	n       int
+	layout  string
+	prompt  string
+	last    string // last expression that parsed
+	history []string
+	save    io.Writer // history file, if any
+}
+
+// run evaluates each line read from in until the end of input or :quit.
+func (r *session) run(in io.Reader) {
+	s := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(r.out, r.prompt)
+		if !s.Scan() {
+			fmt.Fprintln(r.out)
+			return
+		}
+		if !r.line(strings.TrimSpace(s.Text())) {
+			return
+		}
+	}
+}
+
+// line evaluates a line, reporting false if the session should end.
+func (r *session) line(s string) bool {
+	switch {
+	case s == "":
+		return true
+	case s == ":quit" || s == ":q" || s == ":exit":
+		return false
+	case strings.HasPrefix(s, "!"):
+		expr, err := r.recall(s)
+		if err != nil {
+			fmt.Fprintf(r.out, "  %v\n", err)
+			return true
+		}
+		fmt.Fprintf(r.out, "  %s\n", expr)
+		r.remember(expr)
+		r.eval(expr)
+		return true
+	case strings.HasPrefix(s, ":"):
+		r.command(s)
+		return true
+	}
+	r.remember(s)
+	r.eval(s)
+	return true
+}
+
+// command runs a colon command and re-evaluates the last expression that
+// parsed if a setting changed.
+func (r *session) command(s string) {
+	fields := strings.Fields(s)
+	name, arg := fields[0], strings.TrimSpace(strings.TrimPrefix(s, fields[0]))
+	switch name {
+	case ":help", ":h", ":?":
+		fmt.Fprint(r.out, replHelp)
+		return
+	case ":history":
+		for i, expr := range r.history {
+			fmt.Fprintf(r.out, "  %3d  %s\n", i+1, expr)
+		}
+		return
+	case ":tz":
+		if arg == "" {
+			fmt.Fprintf(r.out, "  %s\n", r.loc)
+			return
+		}
+		loc, err := time.LoadLocation(arg)
+		if err != nil {
+			fmt.Fprintf(r.out, "  %v\n", err)
+			return
+		}
+		r.loc = loc
+	case ":at":
+		if arg == "" {
+			t, _ := parseTime(r.at, time.Now(), r.loc)
+			fmt.Fprintf(r.out, "  %s (%s)\n", r.at, t.Format(r.layout))
+			return
+		}
+		_, err := parseTime(arg, time.Now(), r.loc)
+		if err != nil {
+			fmt.Fprintf(r.out, "  %v\n", err)
+			return
+		}
+		r.at = arg
+	case ":n", ":count":
+		if arg == "" {
+			fmt.Fprintf(r.out, "  %d\n", r.n)
+			return
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			fmt.Fprintf(r.out, "  invalid count %q\n", arg)
+			return
+		}
+		r.n = n
+	default:
+		fmt.Fprintf(r.out, "  unknown command %s, type :help for help\n", name)
+		return
+	}
+	if r.last != "" {
+		r.eval(r.last)
+	}
+}
+
+// eval prints the description and next occurrences of an expression, or
+// the parse error marked under the expression.
+func (r *session) eval(s string) {
+	e, err := te.Parse(s, r.loc)
+	if err != nil {
+		if mark := caret(err); mark != "" {
+			fmt.Fprintf(r.out, "  %s\n  %s\n", s, mark)
+		}
+		fmt.Fprintf(r.out, "  %v\n", err)
+		return
+	}
+	r.last = s
+	fmt.Fprintf(r.out, "  %s\n", describe(e))
+	t, err := parseTime(r.at, time.Now(), r.loc)
+	if err != nil {
+		fmt.Fprintf(r.out, "  %v\n", err)
+		return
+	}
+	for i := 0; i < r.n; i++ {
+		t = e.Next(t)
+		if t.IsZero() {
+			fmt.Fprintln(r.out, "  never")
+			return
+		}
+		fmt.Fprintf(r.out, "  %s\n", t.Format(r.layout))
+	}
+}
+
+// recall returns the expression of the history referred to by !N or !!.
+func (r *session) recall(s string) (string, error) {
+	if len(r.history) == 0 {
+		return "", errors.New("history is empty")
+	}
+	if s == "!!" {
+		return r.history[len(r.history)-1], nil
+	}
+	i, err := strconv.Atoi(s[1:])
+	if err != nil || i < 1 || i > len(r.history) {
+		return "", fmt.Errorf("no history entry %s", s)
+	}
+	return r.history[i-1], nil
+}
+
+// remember adds an expression to the history, unless it repeats the
+// last one.
+func (r *session) remember(s string) {
+	if len(r.history) > 0 && r.history[len(r.history)-1] == s {
+		return
+	}
+	r.history = append(r.history, s)
+	if r.save != nil {
+		fmt.Fprintln(r.save, s)
+	}
+}
+
+// caret returns a line marking the offending text of a parse error under
+// its input, or the empty string if err is not a *te.ParseError.
+func caret(err error) string {
+	var e *te.ParseError
+	if !errors.As(err, &e) {
+		return ""
+	}
+	width := utf8.RuneCountInString(e.Token)
+	if width == 0 {
+		width = 1
+	}
+	return strings.Repeat(" ", e.Column-1) + strings.Repeat("^", width)
+}
+
+// maxHistory is the number of expressions loaded from the history file.
+const maxHistory = 1000
+
+func defaultHistory() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".te_history")
+}
+
+// loadHistory returns the most recent expressions of the history file.
+func loadHistory(path string) []string {
+	var history []string
+	f, err := os.Open(path)
+	if err != nil {
+		return history
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			history = append(history, line)
+		}
+	}
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	return history
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+func TestSession(t *testing.T) {
+	var out, saved bytes.Buffer
+	r := &session{out: &out, loc: time.UTC, at: "2026-03-01", n: 2, layout: "Mon Jan 2 15:04 MST", save: &saved}
+	in := strings.Join([]string{
+		"every tue at 9am",
+		":tz Europe/Berlin",
+		"every wendesday",
+		":n 1",
+		":history",
+		"!3",
+		":quit",
+		"daily",
+	}, "\n")
+	r.run(strings.NewReader(in))
+	want := `  on Tuesday at 9am
+  Tue Mar 3 09:00 UTC
+  Tue Mar 10 09:00 UTC
+  on Tuesday at 9am
+  Tue Mar 3 09:00 CET
+  Tue Mar 10 09:00 CET
+  every wendesday
+        ^^^^^^^^^
+  column 7: unknown word, token: "wendesday", did you mean "wednesday"?
+  on Tuesday at 9am
+  Tue Mar 3 09:00 CET
+    1  every tue at 9am
+    2  every wendesday
+  no history entry !3
+`
+	have := out.String()
+	if have != want {
+		t.Errorf("have\n%s\nwant\n%s", have, want)
+	}
+	if saved.String() != "every tue at 9am\nevery wendesday\n" {
+		t.Errorf("saved history %q", saved.String())
+	}
+}
+
+func TestCaret(t *testing.T) {
+	tests := map[string]string{
+		"every tue but":      "          ^^^",
+		"every 5µs at 25:00": "             ^^^^^",
+		"every tue at":       "            ^",
+	}
+	for in, want := range tests {
+		_, err := te.Parse(in, time.UTC)
+		have := caret(err)
+		if have != want {
+			t.Errorf("%s\nhave %q\nwant %q", in, have, want)
+		}
+	}
+	if caret(errors.New("other")) != "" {
+		t.Error("caret of other errors should be empty")
+	}
+}
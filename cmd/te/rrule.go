@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var rruleFreqs = map[string]int{
+	"SECONDLY": levelSecond,
+	"MINUTELY": levelMinute,
+	"HOURLY":   levelHour,
+	"DAILY":    levelDate,
+	"WEEKLY":   levelDate,
+	"MONTHLY":  levelMonth,
+	"YEARLY":   levelYear,
+}
+
+var rruleWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// parseRRule parses an iCalendar recurrence rule such as
+// FREQ=WEEKLY;BYDAY=MO,WE;BYHOUR=9;BYMINUTE=0;BYSECOND=0. Fields that
+// the rule takes from its start are taken from a DTSTART line, if any,
+// and are otherwise an error. Rules that end or repeat at intervals
+// other than 1 have no equivalent.
+func parseRRule(s string) (spec, error) {
+	var rule string
+	var start time.Time
+	for _, line := range strings.Fields(s) {
+		name := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(name, "DTSTART"):
+			i := strings.LastIndex(line, ":")
+			var err error
+			start, err = parseDTStart(line[i+1:])
+			if err != nil {
+				return spec{}, err
+			}
+		case strings.HasPrefix(name, "RRULE:"):
+			rule = line[len("RRULE:"):]
+		default:
+			rule = line
+		}
+	}
+	parts := make(map[string]string)
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return spec{}, fmt.Errorf("rrule: invalid part %q", part)
+		}
+		parts[strings.ToUpper(kv[0])] = strings.ToUpper(kv[1])
+	}
+	freq, ok := rruleFreqs[parts["FREQ"]]
+	if !ok {
+		return spec{}, fmt.Errorf("rrule: invalid FREQ %q", parts["FREQ"])
+	}
+	var sp spec
+	for name, v := range parts {
+		var err error
+		switch name {
+		case "FREQ", "WKST":
+		case "INTERVAL":
+			if v != "1" {
+				return spec{}, errors.New("rrule: INTERVAL other than 1 depends on DTSTART and has no equivalent")
+			}
+		case "COUNT", "UNTIL":
+			return spec{}, fmt.Errorf("rrule: %s has no equivalent, schedules do not end", name)
+		case "BYSECOND":
+			sp.second, err = parseRRuleList(v, 0, 59)
+		case "BYMINUTE":
+			sp.minute, err = parseRRuleList(v, 0, 59)
+		case "BYHOUR":
+			sp.hour, err = parseRRuleList(v, 0, 23)
+		case "BYMONTHDAY":
+			sp.day, err = parseRRuleList(v, -1, 31)
+			if contains(sp.day, 0) {
+				err = errors.New("day 0 out of range")
+			}
+		case "BYMONTH":
+			sp.month, err = parseRRuleList(v, 1, 12)
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				i := indexOf(rruleWeekdays, d)
+				if i < 0 {
+					return spec{}, fmt.Errorf("rrule: unsupported BYDAY %q, only weekdays without a position are supported", d)
+				}
+				sp.weekday = append(sp.weekday, i)
+			}
+			sp.weekday = normalize(sp.weekday)
+		default:
+			return spec{}, fmt.Errorf("rrule: %s is not supported", name)
+		}
+		if err != nil {
+			return spec{}, fmt.Errorf("rrule: %s: %v", name, err)
+		}
+	}
+	// Fields finer than the frequency that are not given are those of
+	// the start of the rule.
+	fromStart := func(level int, values *[]int, v func() int, name string) error {
+		if freq <= level || *values != nil {
+			return nil
+		}
+		if start.IsZero() {
+			return fmt.Errorf("rrule: %s without %s depends on DTSTART", parts["FREQ"], name)
+		}
+		*values = []int{v()}
+		return nil
+	}
+	dated := sp.day != nil || sp.weekday != nil
+	err := fromStart(levelSecond, &sp.second, start.Second, "BYSECOND")
+	if err == nil {
+		err = fromStart(levelMinute, &sp.minute, start.Minute, "BYMINUTE")
+	}
+	if err == nil {
+		err = fromStart(levelHour, &sp.hour, start.Hour, "BYHOUR")
+	}
+	switch {
+	case err != nil:
+	case parts["FREQ"] == "WEEKLY":
+		freq = levelMonth
+		err = fromStart(levelDate, &sp.weekday, func() int { return int(start.Weekday()) }, "BYDAY")
+	case !dated:
+		err = fromStart(levelDate, &sp.day, start.Day, "BYMONTHDAY or BYDAY")
+		if err == nil {
+			err = fromStart(levelMonth, &sp.month, func() int { return int(start.Month()) }, "BYMONTH")
+		}
+	}
+	if err != nil {
+		return spec{}, err
+	}
+	sp.second, sp.minute, sp.hour = full(sp.second, 0, 59), full(sp.minute, 0, 59), full(sp.hour, 0, 23)
+	sp.month, sp.weekday = full(sp.month, 1, 12), full(sp.weekday, 0, 6)
+	return sp, nil
+}
+
+func parseDTStart(s string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("rrule: invalid DTSTART %q", s)
+}
+
+func parseRRuleList(s string, min, max int) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q", part)
+		}
+		if v < min || v > max {
+			return nil, fmt.Errorf("value %d out of range [%d, %d]", v, min, max)
+		}
+		values = append(values, v)
+	}
+	return normalize(values), nil
+}
+
+// formatRRule formats a spec as a recurrence rule. Every field finer than
+// the frequency is given so that the rule does not depend on its start.
+func formatRRule(sp spec) (string, error) {
+	switch {
+	case sp.dayOr && sp.day != nil && sp.weekday != nil:
+		return "", errors.New("RRULE matches both the day of the month and the weekday, not either")
+	case sp.year != nil:
+		return "", errors.New("RRULE has no years")
+	}
+	freq := "YEARLY"
+	switch {
+	case sp.second == nil:
+		freq = "SECONDLY"
+	case sp.minute == nil:
+		freq = "MINUTELY"
+	case sp.hour == nil:
+		freq = "HOURLY"
+	case sp.day == nil && sp.weekday == nil:
+		freq = "DAILY"
+	case sp.day == nil && sp.month == nil:
+		freq = "WEEKLY"
+	case sp.month == nil:
+		freq = "MONTHLY"
+	}
+	parts := []string{"FREQ=" + freq}
+	list := func(name string, values []int, format func(int) string) {
+		if values == nil {
+			return
+		}
+		s := make([]string, len(values))
+		for i, v := range values {
+			s[i] = format(v)
+		}
+		parts = append(parts, name+"="+strings.Join(s, ","))
+	}
+	list("BYMONTH", sp.month, strconv.Itoa)
+	list("BYMONTHDAY", sp.day, strconv.Itoa)
+	list("BYDAY", sp.weekday, func(v int) string { return rruleWeekdays[v] })
+	list("BYHOUR", sp.hour, strconv.Itoa)
+	list("BYMINUTE", sp.minute, strconv.Itoa)
+	list("BYSECOND", sp.second, strconv.Itoa)
+	return strings.Join(parts, ";"), nil
+}
+
+func indexOf(values []string, s string) int {
+	for i, v := range values {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+// run runs a command on each occurrence of an expression until it is
+// interrupted, then waits for a running command to finish. Occurrences
+// before now are not run.
+func run(args []string) error {
+	var o options
+	fs := newFlagSet("te run", "[OPTIONS] EXPR -- COMMAND [ARGS]")
+	o.register(fs)
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	args = fs.Args()
+	i := 0
+	for i < len(args) && args[i] != "--" {
+		i++
+	}
+	if i >= len(args)-1 {
+		return errors.New("missing command, expected EXPR -- COMMAND [ARGS]")
+	}
+	e, _, t, err := o.parse(args[:i])
+	if err != nil {
+		return err
+	}
+	r := newRunner(args[i+1:], log.New(os.Stderr, "te: ", log.LstdFlags))
+	if now := time.Now(); t.Before(now) {
+		t = now
+	}
+	ctx, cancel := interruptContext()
+	defer cancel()
+	for {
+		d := te.Until(e, t)
+		if d <= 0 {
+			r.log.Print(errNever)
+			break
+		}
+		next := t.Add(d)
+		if !sleepUntil(ctx, next) {
+			r.log.Print("interrupted")
+			break
+		}
+		r.start(next)
+		t = next
+	}
+	r.wait()
+	return nil
+}
+
+// runner runs a command, skipping runs while the previous run has not
+// finished.
+type runner struct {
+	cmd  []string
+	log  *log.Logger
+	busy chan struct{}
+}
+
+func newRunner(cmd []string, l *log.Logger) *runner {
+	return &runner{cmd: cmd, log: l, busy: make(chan struct{}, 1)}
+}
+
+// start runs the command for the occurrence at t in the background. It
+// reports false if the occurrence was skipped.
+func (r *runner) start(t time.Time) bool {
+	select {
+	case r.busy <- struct{}{}:
+	default:
+		r.log.Printf("skipping %s, previous run has not finished", t.Format(time.RFC3339))
+		return false
+	}
+	go func() {
+		r.run(t)
+		<-r.busy
+	}()
+	return true
+}
+
+// run runs the command for the occurrence at t and logs its exit status.
+// The occurrence is passed to the command in TE_OCCURRENCE.
+func (r *runner) run(t time.Time) {
+	name := strings.Join(r.cmd, " ")
+	cmd := exec.Command(r.cmd[0], r.cmd[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "TE_OCCURRENCE="+t.Format(time.RFC3339))
+	r.log.Printf("running %s for %s", name, t.Format(time.RFC3339))
+	start := time.Now()
+	err := cmd.Run()
+	elapsed := time.Since(start).Round(time.Millisecond)
+	var exit *exec.ExitError
+	switch {
+	case err == nil:
+		r.log.Printf("%s exited with status 0 after %v", name, elapsed)
+	case errors.As(err, &exit) && exit.ExitCode() >= 0:
+		r.log.Printf("%s exited with status %d after %v", name, exit.ExitCode(), elapsed)
+	default:
+		r.log.Printf("%s failed after %v: %v", name, elapsed, err)
+	}
+}
+
+// wait waits for a running command to finish.
+func (r *runner) wait() {
+	r.busy <- struct{}{}
+	<-r.busy
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSleepUntil(t *testing.T) {
+	if !sleepUntil(context.Background(), time.Now().Add(-time.Second)) {
+		t.Fatal("past time should be reached")
+	}
+	if !sleepUntil(context.Background(), time.Now().Add(10*time.Millisecond)) {
+		t.Fatal("near time should be reached")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if sleepUntil(ctx, time.Now().Add(time.Hour)) {
+		t.Fatal("canceled sleep should not be reached")
+	}
+}
+
+func TestRunner(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		cmd  []string
+		want string
+	}{
+		"success": {[]string{"true"}, "true exited with status 0"},
+		"failure": {[]string{"sh", "-c", "exit 3"}, "sh -c exit 3 exited with status 3"},
+		"missing": {[]string{"te-no-such-command"}, "te-no-such-command failed"},
+		"occurrence": {
+			[]string{"sh", "-c", `test "$TE_OCCURRENCE" = 2026-03-01T09:00:00Z`},
+			"exited with status 0",
+		},
+	}
+	for name, tt := range tests {
+		var buf bytes.Buffer
+		r := newRunner(tt.cmd, log.New(&buf, "", 0))
+		r.run(at)
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("%s\nhave %q\nwant %q", name, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestRunnerOverlap(t *testing.T) {
+	var buf bytes.Buffer
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	r := newRunner([]string{"sleep", "0.2"}, log.New(&buf, "", 0))
+	if !r.start(at) {
+		t.Fatal("first run should start")
+	}
+	if r.start(at.Add(time.Minute)) {
+		t.Fatal("overlapping run should be skipped")
+	}
+	r.wait()
+	if !r.start(at.Add(2 * time.Minute)) {
+		t.Fatal("run after the previous run finished should start")
+	}
+	r.wait()
+	if !strings.Contains(buf.String(), "skipping 2026-03-01T09:01:00Z") {
+		t.Errorf("missing skip in log %q", buf.String())
+	}
+}
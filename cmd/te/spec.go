@@ -0,0 +1,574 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+// Levels of a spec, from the finest to the coarsest. The date level is
+// both the day of the month and the weekday.
+const (
+	levelSecond = iota
+	levelMinute
+	levelHour
+	levelDate
+	levelMonth
+	levelYear
+)
+
+// spec is a schedule as the values allowed for each calendar field, the
+// form shared by cron, systemd calendar events and recurrence rules. A
+// nil field allows any value. The schedule occurs at each second whose
+// fields are all allowed. If dayOr is set, as in cron, a date restricted
+// by both its day and its weekday is allowed if either is.
+type spec struct {
+	second  []int
+	minute  []int
+	hour    []int
+	day     []int // 1 to 31, or -1 for the last day of the month
+	weekday []int // 0 is Sunday
+	month   []int
+	year    []int
+	dayOr   bool
+}
+
+// isAny reports whether all values of a level are allowed.
+func (s spec) isAny(level int) bool {
+	switch level {
+	case levelSecond:
+		return s.second == nil
+	case levelMinute:
+		return s.minute == nil
+	case levelHour:
+		return s.hour == nil
+	case levelDate:
+		return s.day == nil && s.weekday == nil
+	case levelMonth:
+		return s.month == nil
+	}
+	return s.year == nil
+}
+
+// isFirst reports whether only the first value of a level is allowed,
+// which is implied below the finest level of an expression.
+func (s spec) isFirst(level int) bool {
+	switch level {
+	case levelSecond:
+		return equal(s.second, 0)
+	case levelMinute:
+		return equal(s.minute, 0)
+	case levelHour:
+		return equal(s.hour, 0)
+	case levelDate:
+		return equal(s.day, 1) && s.weekday == nil
+	case levelMonth:
+		return equal(s.month, 1)
+	}
+	return false
+}
+
+// finest returns the finest level that must be expressed. Expressions
+// occur at the start of their finest level, so the first values of the
+// levels below it are implied. If the first level that is not at its
+// first value allows any value, the level below it is expressed so that
+// the schedule occurs at each of those values.
+func (s spec) finest() int {
+	level := levelSecond
+	for level < levelYear && s.isFirst(level) {
+		level++
+	}
+	if s.isAny(level) && level > levelSecond {
+		return level - 1
+	}
+	return level
+}
+
+// expr returns the expression for the schedule.
+func (s spec) expr() te.Expression {
+	from := s.finest()
+	var exprs []te.Expression
+	if from == levelSecond && s.second == nil {
+		exprs = append(exprs, te.Secondly(1))
+	}
+	for level := from; level <= levelYear; level++ {
+		if !s.isAny(level) {
+			exprs = append(exprs, s.levelExpr(level))
+		}
+	}
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return te.Intersect(exprs...)
+}
+
+func (s spec) levelExpr(level int) te.Expression {
+	switch level {
+	case levelSecond:
+		return stepExpr(s.second, 59, te.Second, te.SecondStep)
+	case levelMinute:
+		return stepExpr(s.minute, 59, te.Minute, te.MinuteStep)
+	case levelHour:
+		return stepExpr(s.hour, 23, te.Hour, te.HourStep)
+	case levelDate:
+		var exprs []te.Expression
+		if s.day != nil {
+			exprs = append(exprs, unionOf(s.day, te.Day))
+		}
+		if s.weekday != nil {
+			exprs = append(exprs, unionOf(s.weekday, func(v int) te.Expression { return te.Weekday(time.Weekday(v)) }))
+		}
+		switch {
+		case len(exprs) == 1:
+			return exprs[0]
+		case s.dayOr:
+			return te.Union(exprs...)
+		}
+		return te.Intersect(exprs...)
+	case levelMonth:
+		return unionOf(s.month, func(v int) te.Expression { return te.Month(time.Month(v)) })
+	}
+	return unionOf(s.year, te.Year)
+}
+
+func stepExpr(values []int, max int, one func(int) te.Expression, step func(n, offset int) te.Expression) te.Expression {
+	if n, offset, ok := progression(values, max); ok {
+		return step(n, offset)
+	}
+	return unionOf(values, one)
+}
+
+func unionOf(values []int, fn func(int) te.Expression) te.Expression {
+	if len(values) == 1 {
+		return fn(values[0])
+	}
+	exprs := make([]te.Expression, len(values))
+	for i, v := range values {
+		exprs[i] = fn(v)
+	}
+	return te.Union(exprs...)
+}
+
+// Fields of a spec collected from an expression.
+const (
+	fieldSecond = iota
+	fieldMinute
+	fieldHour
+	fieldDay
+	fieldWeekday
+	fieldMonth
+	fieldYear
+	numFields
+)
+
+var fieldLevels = [numFields]int{levelSecond, levelMinute, levelHour, levelDate, levelDate, levelMonth, levelYear}
+
+var fieldRanges = [numFields][2]int{{0, 59}, {0, 59}, {0, 23}, {1, 31}, {0, 6}, {1, 12}, {1, 9999}}
+
+// errNotCalendar is returned for expressions that are not calendar fields.
+var errNotCalendar = errors.New("not a combination of calendar fields")
+
+// specOf returns the spec of an expression that is an intersection of
+// calendar fields, each of which may be a union of values.
+func specOf(expr te.Expression) (spec, error) {
+	var c collector
+	err := c.add(expr)
+	if err != nil {
+		return spec{}, err
+	}
+	finest := -1
+	for level := levelSecond; level <= levelYear; level++ {
+		if c.mentioned[level] {
+			finest = level
+			break
+		}
+	}
+	if finest < 0 {
+		return spec{}, errNotCalendar
+	}
+	for f := 0; f < numFields; f++ {
+		if fieldLevels[f] >= finest || f == fieldWeekday {
+			continue
+		}
+		c.fields[f] = []int{fieldRanges[f][0]}
+	}
+	for f, values := range c.excluded {
+		if values != nil {
+			err := c.subtract(f, values)
+			if err != nil {
+				return spec{}, err
+			}
+		}
+	}
+	for f, values := range c.fields {
+		if values != nil && len(values) == 0 {
+			return spec{}, errors.New("never occurs")
+		}
+		if f != fieldYear && len(values) == fieldRanges[f][1]-fieldRanges[f][0]+1 {
+			c.fields[f] = nil
+		}
+	}
+	f := c.fields
+	return spec{f[fieldSecond], f[fieldMinute], f[fieldHour], f[fieldDay], f[fieldWeekday], f[fieldMonth], f[fieldYear], c.dayOr}, nil
+}
+
+type collector struct {
+	fields    [numFields][]int
+	excluded  [numFields][]int
+	mentioned [levelYear + 1]bool
+	dayOr     bool
+}
+
+func (c *collector) add(expr te.Expression) error {
+	n := te.Inspect(expr)
+	switch n.Kind {
+	case te.KindIntersect:
+		for _, e := range n.Children {
+			err := c.add(e)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case te.KindDaily:
+		c.mentioned[levelDate] = true
+		return nil
+	case te.KindExcept:
+		for _, e := range n.Children {
+			err := c.exclude(e)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case te.KindUnion:
+		var sets [numFields][]int
+		used := 0
+		for _, e := range n.Children {
+			f, values, err := fieldOf(e)
+			if err != nil {
+				return err
+			}
+			if sets[f] == nil {
+				used++
+			}
+			sets[f] = append(sets[f], values...)
+		}
+		switch {
+		case used == 1:
+		case used == 2 && sets[fieldDay] != nil && sets[fieldWeekday] != nil:
+			if c.fields[fieldDay] != nil || c.fields[fieldWeekday] != nil || c.dayOr {
+				return fmt.Errorf("%q restricts days that are already restricted", describe(expr))
+			}
+			c.dayOr = true
+		default:
+			return fmt.Errorf("%q is %v", describe(expr), errNotCalendar)
+		}
+		for f, values := range sets {
+			if values != nil {
+				c.restrict(f, values)
+			}
+		}
+		return nil
+	}
+	f, values, err := fieldOf(expr)
+	if err != nil {
+		return err
+	}
+	c.restrict(f, values)
+	return nil
+}
+
+// restrict allows only values of field f that are in values.
+func (c *collector) restrict(f int, values []int) {
+	c.mentioned[fieldLevels[f]] = true
+	values = normalize(values)
+	if c.fields[f] == nil {
+		c.fields[f] = values
+		return
+	}
+	rv := make([]int, 0)
+	for _, v := range c.fields[f] {
+		if contains(values, v) {
+			rv = append(rv, v)
+		}
+	}
+	c.fields[f] = rv
+}
+
+// exclude excludes the values of the calendar fields of expr, which may
+// be a union of fields. Excluding a union excludes each of its values.
+func (c *collector) exclude(expr te.Expression) error {
+	n := te.Inspect(expr)
+	if n.Kind == te.KindUnion {
+		for _, e := range n.Children {
+			err := c.exclude(e)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	f, values, err := fieldOf(expr)
+	if err != nil {
+		return fmt.Errorf("except %v", err)
+	}
+	c.mentioned[fieldLevels[f]] = true
+	c.excluded[f] = append(c.excluded[f], values...)
+	return nil
+}
+
+// subtract removes the excluded values of field f from those allowed.
+func (c *collector) subtract(f int, values []int) error {
+	switch {
+	case (f == fieldDay || f == fieldWeekday) && c.dayOr:
+		return errors.New("cannot exclude days from a schedule on either a day of the month or a weekday")
+	case f == fieldDay && (contains(values, -1) || contains(c.fields[f], -1)):
+		return errors.New("cannot exclude days together with the last day of the month")
+	case f == fieldYear && c.fields[f] == nil:
+		return errors.New("cannot exclude years from a schedule in every year")
+	}
+	allowed := c.fields[f]
+	if allowed == nil {
+		allowed = steps(1, fieldRanges[f][0], fieldRanges[f][1])
+	}
+	rv := make([]int, 0)
+	for _, v := range allowed {
+		if !contains(values, v) {
+			rv = append(rv, v)
+		}
+	}
+	c.fields[f] = rv
+	return nil
+}
+
+// fieldOf returns the field and values of a single calendar field.
+func fieldOf(expr te.Expression) (int, []int, error) {
+	n := te.Inspect(expr)
+	arg := func(i int) int { return n.Args[i].(int) }
+	switch n.Kind {
+	case te.KindHour:
+		return fieldHour, []int{arg(0)}, nil
+	case te.KindHourStep:
+		return fieldHour, steps(arg(0), arg(1), 23), nil
+	case te.KindMinute:
+		return fieldMinute, []int{arg(0)}, nil
+	case te.KindMinuteStep:
+		return fieldMinute, steps(arg(0), arg(1), 59), nil
+	case te.KindSecond:
+		return fieldSecond, []int{arg(0)}, nil
+	case te.KindSecondStep:
+		return fieldSecond, steps(arg(0), arg(1), 59), nil
+	case te.KindDay:
+		return fieldDay, []int{arg(0)}, nil
+	case te.KindWeekday:
+		return fieldWeekday, []int{int(n.Args[0].(time.Weekday))}, nil
+	case te.KindMonth:
+		return fieldMonth, []int{int(n.Args[0].(time.Month))}, nil
+	case te.KindYear:
+		return fieldYear, []int{arg(0)}, nil
+	case te.KindNil:
+		return 0, nil, fmt.Errorf("invalid expression: %v", n.Err)
+	}
+	return 0, nil, fmt.Errorf("%q is %v", describe(expr), errNotCalendar)
+}
+
+// steps returns the values from offset to max in steps of n.
+func steps(n, offset, max int) []int {
+	var values []int
+	for v := offset; v <= max; v += n {
+		values = append(values, v)
+	}
+	return values
+}
+
+// progression returns the step and offset of values that are every n-th
+// value from offset to max, if there are at least two values.
+func progression(values []int, max int) (n, offset int, ok bool) {
+	if len(values) < 2 {
+		return 0, 0, false
+	}
+	n, offset = values[1]-values[0], values[0]
+	for i := 1; i < len(values); i++ {
+		if values[i]-values[i-1] != n {
+			return 0, 0, false
+		}
+	}
+	return n, offset, values[len(values)-1]+n > max
+}
+
+// normalize sorts values and removes duplicates.
+func normalize(values []int) []int {
+	if values == nil {
+		return nil
+	}
+	sort.Ints(values)
+	rv := make([]int, 0, len(values))
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			rv = append(rv, v)
+		}
+	}
+	return rv
+}
+
+func contains(values []int, v int) bool {
+	for _, w := range values {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(values []int, v int) bool {
+	return len(values) == 1 && values[0] == v
+}
+
+// compareWindow is the period over which equivalent compares days.
+const compareWindow = 366 * 24 * time.Hour
+
+// equivalent reports whether two expressions have the same occurrences
+// after t, as far as can be compared. All occurrences in the day after t
+// are compared, then the first occurrence of each day in the window. As
+// the times of day of a spec are the same on each day it occurs, this
+// finds the differences between specs.
+func equivalent(a, b te.Expression, t time.Time) bool {
+	end := t.Add(24 * time.Hour)
+	for ta, tb := t, t; ta.Before(end); {
+		ta, tb = a.Next(ta), b.Next(tb)
+		if !ta.Equal(tb) {
+			return false
+		}
+		if ta.IsZero() {
+			return true
+		}
+	}
+	year, month, day := t.Date()
+	for i := 1; i <= int(compareWindow/(24*time.Hour)); i++ {
+		d := time.Date(year, month, day+i, 0, 0, 0, -1, t.Location())
+		if !a.Next(d).Equal(b.Next(d)) {
+			return false
+		}
+	}
+	return true
+}
+
+// syntax describes how the fields of a schedule language are written.
+type syntax struct {
+	rng   string         // separator of ranges, such as "-" in 1-5
+	names map[string]int // names of values, in lower case
+	name  func(int) string
+	pad   bool // write numbers with two digits
+
+	// closed writes steps as */15 from the minimum and as ranges such as
+	// 5-59/15 otherwise, rather than as 00/15 and 05/15.
+	closed bool
+}
+
+// parseField parses a comma separated list of values, ranges and steps
+// of a field in [min, max]. An asterisk allows any value.
+func (sx syntax) parseField(s string, min, max int) ([]int, error) {
+	if s == "*" {
+		return nil, nil
+	}
+	var values []int
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, n := min, max, 1
+		rng := part
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			n, err = strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+			rng = part[:i]
+		}
+		if rng != "*" {
+			bounds := strings.SplitN(rng, sx.rng, 2)
+			v, err := sx.value(bounds[0], min, max)
+			if err != nil {
+				return nil, err
+			}
+			lo = v
+			switch {
+			case len(bounds) == 2:
+				hi, err = sx.value(bounds[1], min, max)
+				if err != nil {
+					return nil, err
+				}
+			case n == 1:
+				hi = lo
+			}
+		}
+		if lo > hi {
+			return nil, fmt.Errorf("invalid range %q", part)
+		}
+		values = append(values, steps(n, lo, hi)...)
+	}
+	return normalize(values), nil
+}
+
+func (sx syntax) value(s string, min, max int) (int, error) {
+	v, ok := sx.names[strings.ToLower(s)]
+	if !ok {
+		var err error
+		v, err = strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value %q", s)
+		}
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, min, max)
+	}
+	return v, nil
+}
+
+// formatField formats the values of a field in [min, max]. If step is
+// true, every n-th value to max is written as a step, such as */15.
+func (sx syntax) formatField(values []int, min, max int, step bool) string {
+	if values == nil {
+		return "*"
+	}
+	if n, offset, ok := progression(values, max); ok && step && n > 1 && len(values) > 2 {
+		switch {
+		case !sx.closed:
+			return fmt.Sprintf("%s/%d", sx.format(offset), n)
+		case offset == min:
+			return fmt.Sprintf("*/%d", n)
+		}
+		return fmt.Sprintf("%s%s%s/%d", sx.format(offset), sx.rng, sx.format(max), n)
+	}
+	var parts []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			parts = append(parts, sx.format(values[i])+sx.rng+sx.format(values[j]))
+		case j > i:
+			parts = append(parts, sx.format(values[i]), sx.format(values[j]))
+		default:
+			parts = append(parts, sx.format(values[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+func (sx syntax) format(v int) string {
+	switch {
+	case sx.name != nil:
+		return sx.name(v)
+	case sx.pad:
+		return fmt.Sprintf("%02d", v)
+	}
+	return strconv.Itoa(v)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var systemdShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+var (
+	systemdSyntax        = syntax{rng: "..", pad: true}
+	systemdYearSyntax    = syntax{rng: ".."}
+	systemdWeekdaySyntax = syntax{rng: "..", names: systemdWeekdays, name: func(v int) string {
+		return time.Weekday(v).String()[:3]
+	}}
+)
+
+var systemdWeekdays = map[string]int{
+	"sun": 0, "sunday": 0,
+	"mon": 1, "monday": 1,
+	"tue": 2, "tuesday": 2,
+	"wed": 3, "wednesday": 3,
+	"thu": 4, "thursday": 4,
+	"fri": 5, "friday": 5,
+	"sat": 6, "saturday": 6,
+}
+
+// parseSystemd parses a systemd calendar event, as used by OnCalendar,
+// of the form [WEEKDAYS] [[YEAR-]MONTH-DAY] [HOUR:MINUTE[:SECOND]], or
+// one of its shorthands such as daily. The last day of the month may be
+// written as ~01. Time zones are not supported.
+func parseSystemd(s string) (spec, error) {
+	s = strings.TrimSpace(s)
+	if v, ok := systemdShorthands[strings.ToLower(s)]; ok {
+		s = v
+	}
+	sp := spec{second: []int{0}, minute: []int{0}, hour: []int{0}}
+	var err error
+	fields := strings.Fields(s)
+	if len(fields) > 0 && isLetter(fields[0][0]) {
+		sp.weekday, err = systemdWeekdaySyntax.parseField(fields[0], 0, 6)
+		if err != nil {
+			return spec{}, fmt.Errorf("systemd: weekday: %v", err)
+		}
+		fields = fields[1:]
+	}
+	if len(fields) > 0 && !strings.Contains(fields[0], ":") {
+		err = parseSystemdDate(&sp, fields[0])
+		if err != nil {
+			return spec{}, err
+		}
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		err = parseSystemdTime(&sp, fields[0])
+		if err != nil {
+			return spec{}, err
+		}
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		return spec{}, fmt.Errorf("systemd: unsupported %q, time zones are not supported", fields[0])
+	}
+	sp.weekday = full(sp.weekday, 0, 6)
+	return sp, nil
+}
+
+func parseSystemdDate(sp *spec, s string) error {
+	var err error
+	var last bool
+	if i := strings.Index(s, "~"); i >= 0 {
+		if s[i+1:] != "01" {
+			return fmt.Errorf("systemd: only ~01, the last day of the month, is supported")
+		}
+		s, last = s[:i]+"-*", true
+	}
+	parts := strings.Split(s, "-")
+	switch len(parts) {
+	case 2:
+		parts = append([]string{"*"}, parts...)
+	case 3:
+	default:
+		return fmt.Errorf("systemd: invalid date %q", s)
+	}
+	sp.year, err = systemdYearSyntax.parseField(parts[0], 1, 9999)
+	if err != nil {
+		return fmt.Errorf("systemd: year: %v", err)
+	}
+	sp.month, err = systemdSyntax.parseField(parts[1], 1, 12)
+	if err != nil {
+		return fmt.Errorf("systemd: month: %v", err)
+	}
+	sp.month = full(sp.month, 1, 12)
+	if last {
+		sp.day = []int{-1}
+		return nil
+	}
+	sp.day, err = systemdSyntax.parseField(parts[2], 1, 31)
+	if err != nil {
+		return fmt.Errorf("systemd: day: %v", err)
+	}
+	sp.day = full(sp.day, 1, 31)
+	return nil
+}
+
+func parseSystemdTime(sp *spec, s string) error {
+	parts := strings.Split(s, ":")
+	switch len(parts) {
+	case 2:
+		parts = append(parts, "00")
+	case 3:
+		if strings.Contains(parts[2], ".") {
+			return errors.New("systemd: fractional seconds are not supported")
+		}
+	default:
+		return fmt.Errorf("systemd: invalid time %q", s)
+	}
+	var err error
+	sp.hour, err = systemdSyntax.parseField(parts[0], 0, 23)
+	if err != nil {
+		return fmt.Errorf("systemd: hour: %v", err)
+	}
+	sp.minute, err = systemdSyntax.parseField(parts[1], 0, 59)
+	if err != nil {
+		return fmt.Errorf("systemd: minute: %v", err)
+	}
+	sp.second, err = systemdSyntax.parseField(parts[2], 0, 59)
+	if err != nil {
+		return fmt.Errorf("systemd: second: %v", err)
+	}
+	sp.hour, sp.minute, sp.second = full(sp.hour, 0, 23), full(sp.minute, 0, 59), full(sp.second, 0, 59)
+	return nil
+}
+
+// formatSystemd formats a spec as a systemd calendar event.
+func formatSystemd(sp spec) (string, error) {
+	if sp.dayOr && sp.day != nil && sp.weekday != nil {
+		return "", errors.New("systemd matches both the day of the month and the weekday, not either")
+	}
+	var b strings.Builder
+	if sp.weekday != nil {
+		b.WriteString(systemdWeekdaySyntax.formatField(sp.weekday, 0, 6, false) + " ")
+	}
+	b.WriteString(systemdYearSyntax.formatField(sp.year, 1, 9999, false))
+	b.WriteString("-" + systemdSyntax.formatField(sp.month, 1, 12, true))
+	switch {
+	case equal(sp.day, -1):
+		b.WriteString("~01")
+	case contains(sp.day, -1):
+		return "", errors.New("systemd cannot combine the last day of the month with other days")
+	default:
+		b.WriteString("-" + systemdSyntax.formatField(sp.day, 1, 31, true))
+	}
+	fmt.Fprintf(&b, " %s:%s:%s",
+		systemdSyntax.formatField(sp.hour, 0, 23, true),
+		systemdSyntax.formatField(sp.minute, 0, 59, true),
+		systemdSyntax.formatField(sp.second, 0, 59, true))
+	return b.String(), nil
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// zoneTable writes occurrences as a table with a column per location.
+type zoneTable struct {
+	w      *tabwriter.Writer
+	zones  []*time.Location
+	layout string
+}
+
+// newZoneTable returns a table of the comma separated locations in zones,
+// followed by UTC if utc is set, and writes its header row.
+func newZoneTable(w io.Writer, zones string, utc bool, layout string) (*zoneTable, error) {
+	z := &zoneTable{w: tabwriter.NewWriter(w, 0, 8, 2, ' ', 0), layout: layout}
+	for _, name := range strings.Split(zones, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, err
+		}
+		z.zones = append(z.zones, loc)
+	}
+	if utc {
+		z.zones = append(z.zones, time.UTC)
+	}
+	if len(z.zones) == 0 {
+		return nil, fmt.Errorf("invalid zones %q, expected a comma separated list of locations", zones)
+	}
+	names := make([]string, len(z.zones))
+	for i, loc := range z.zones {
+		names[i] = loc.String()
+	}
+	_, err := fmt.Fprintln(z.w, strings.Join(names, "\t"))
+	return z, err
+}
+
+// write writes a row for the occurrence at t.
+func (z *zoneTable) write(t time.Time) error {
+	cells := make([]string, len(z.zones))
+	for i, loc := range z.zones {
+		cells[i] = t.In(loc).Format(z.layout)
+	}
+	_, err := fmt.Fprintln(z.w, strings.Join(cells, "\t"))
+	return err
+}
+
+// flush aligns and writes the rows of the table.
+func (z *zoneTable) flush() error {
+	return z.w.Flush()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestZoneTable(t *testing.T) {
+	var buf bytes.Buffer
+	z, err := newZoneTable(&buf, "America/New_York, Europe/London,Asia/Tokyo", true, "Mon Jan 2 15:04 MST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, occ := range []time.Time{
+		time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC),
+	} {
+		err = z.write(occ)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = z.flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `America/New_York     Europe/London        Asia/Tokyo           UTC
+Mon Mar 2 09:00 EST  Mon Mar 2 14:00 GMT  Mon Mar 2 23:00 JST  Mon Mar 2 14:00 UTC
+Mon Mar 9 09:00 EDT  Mon Mar 9 13:00 GMT  Mon Mar 9 22:00 JST  Mon Mar 9 13:00 UTC
+`
+	if buf.String() != want {
+		t.Errorf("have\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestZoneTableError(t *testing.T) {
+	for _, zones := range []string{"Mars/Base", " , "} {
+		var buf bytes.Buffer
+		_, err := newZoneTable(&buf, zones, false, time.RFC3339)
+		if err == nil {
+			t.Errorf("%q: expected error", zones)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+// layouts are the layouts accepted for reference times in addition to
+// RFC 3339, Unix seconds and durations relative to now.
+var layouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	"Jan 2 2006",
+	"Jan 2, 2006",
+	"Jan 2 2006 15:04",
+	"Jan 2, 2006 15:04",
+	"January 2 2006",
+	"January 2, 2006",
+	"January 2 2006 15:04",
+	"January 2, 2006 15:04",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2006",
+	"January 2006",
+}
+
+// parseTime parses a reference time in loc. The time may be "now",
+// "today", "yesterday" or "tomorrow", an RFC 3339 time, Unix seconds,
+// a duration relative to now such as -72h, or a date with an optional
+// time of day such as 2026-03-29 15:04 or March 29, 2026.
+func parseTime(s string, now time.Time, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	now = now.In(loc)
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	switch strings.ToLower(s) {
+	case "", "now":
+		return now, nil
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.In(loc), nil
+	}
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0).In(loc), nil
+	}
+	if s[0] == '+' || s[0] == '-' {
+		if d, err := time.ParseDuration(s); err == nil {
+			return now.Add(d), nil
+		}
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// maxLookback is how far prev searches for an earlier occurrence.
+const maxLookback = 200 * 365 * 24 * time.Hour
+
+// prev returns the latest occurrence of expr before t, or the zero time
+// if there is none within maxLookback. Expressions only generate times
+// forward, so prev searches windows of increasing size before t.
+func prev(expr te.Expression, t time.Time) time.Time {
+	for w := time.Second; ; w *= 2 {
+		if w > maxLookback {
+			w = maxLookback
+		}
+		var last time.Time
+		for next := expr.Next(t.Add(-w - 1)); !next.IsZero() && next.Before(t); next = expr.Next(next) {
+			last = next
+		}
+		if !last.IsZero() {
+			return last
+		}
+		if w == maxLookback {
+			return time.Time{}
+		}
+	}
+}
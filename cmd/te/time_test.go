@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 30, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"now":                       now,
+		"yesterday":                 time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC),
+		"2026-03-29T01:30:00Z":      time.Date(2026, 3, 29, 1, 30, 0, 0, time.UTC),
+		"1700000000":                time.Unix(1700000000, 0),
+		"-72h":                      now.Add(-72 * time.Hour),
+		"2026-03-29":                time.Date(2026, 3, 29, 0, 0, 0, 0, time.UTC),
+		"2026-03-29 15:04":          time.Date(2026, 3, 29, 15, 4, 0, 0, time.UTC),
+		"March 29, 2026":            time.Date(2026, 3, 29, 0, 0, 0, 0, time.UTC),
+		"Mar 29 2026 15:04":         time.Date(2026, 3, 29, 15, 4, 0, 0, time.UTC),
+		"29 March 2026":             time.Date(2026, 3, 29, 0, 0, 0, 0, time.UTC),
+		"March 2026":                time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		"2026-03-29T15:04:05":       time.Date(2026, 3, 29, 15, 4, 5, 0, time.UTC),
+		"2026-03-29T15:04:05+01:00": time.Date(2026, 3, 29, 14, 4, 5, 0, time.UTC),
+	}
+	for in, want := range tests {
+		have, err := parseTime(in, now, time.UTC)
+		if err != nil {
+			t.Errorf("parseTime(%q) %v", in, err)
+			continue
+		}
+		if !have.Equal(want) {
+			t.Errorf("parseTime(%q)\nhave %v\nwant %v", in, have, want)
+		}
+	}
+	_, err := parseTime("next fortnight", now, time.UTC)
+	if err == nil {
+		t.Errorf("want error")
+	}
+}
+
+func TestPrev(t *testing.T) {
+	from := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		expr te.Expression
+		want time.Time
+	}{
+		"dense": {
+			te.Secondly(1),
+			time.Date(2026, 3, 14, 23, 59, 59, 0, time.UTC),
+		},
+		"weekly": {
+			te.Intersect(te.Weekday(time.Monday), te.Hour(9)),
+			time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC),
+		},
+		"yearly": {
+			te.Date(time.December, 25),
+			time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC),
+		},
+		"none": {
+			te.Year(2027),
+			time.Time{},
+		},
+	}
+	for name, tt := range tests {
+		have := prev(tt.expr, from)
+		if !have.Equal(tt.want) {
+			t.Errorf("%s\nhave %v\nwant %v", name, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pnelson/te"
+)
+
+// errNever is returned when an expression has no next occurrence.
+var errNever = errors.New("expression is never active again")
+
+// wait blocks until the next occurrence of an expression after the
+// reference time. It exits with status 130 if interrupted.
+func wait(args []string) error {
+	var o options
+	fs := newFlagSet("te wait", "[OPTIONS] EXPR")
+	o.register(fs)
+	v := fs.Bool("v", false, "print the occurrence waited for")
+	f := fs.String("f", "Mon Jan 2 15:04 MST", "time format layout")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	e, _, t, err := o.parse(fs.Args())
+	if err != nil {
+		return err
+	}
+	d := te.Until(e, t)
+	if d <= 0 {
+		return errNever
+	}
+	next := t.Add(d)
+	if *v {
+		fmt.Fprintf(os.Stderr, "waiting until %s\n", next.Format(*f))
+	}
+	ctx, cancel := interruptContext()
+	defer cancel()
+	if !sleepUntil(ctx, next) {
+		return exitError(130)
+	}
+	return nil
+}
+
+// maxSleep bounds each sleep so that suspends and changes to the wall
+// clock delay an occurrence by at most this long.
+const maxSleep = time.Minute
+
+// sleepUntil waits until t, reporting false if ctx is done first.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	for {
+		d := time.Until(t)
+		if d <= 0 {
+			return true
+		}
+		if d > maxSleep {
+			d = maxSleep
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+	}
+}
+
+// interruptContext returns a context that is done on SIGINT or SIGTERM.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-c:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(c)
+		cancel()
+	}
+}
@@ -0,0 +1,277 @@
+package te
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GapPolicy determines how wall clock times that do not exist because
+// clocks were set forward for daylight saving time are handled.
+type GapPolicy int
+
+const (
+	// GapShift shifts nonexistent times forward by the length of the
+	// gap. For example, 2:30am becomes 3:30am when clocks are set
+	// forward from 2am to 3am. This is the default policy.
+	GapShift GapPolicy = iota
+
+	// GapSkip skips nonexistent times.
+	GapSkip
+)
+
+func (p GapPolicy) String() string {
+	switch p {
+	case GapShift:
+		return "shift"
+	case GapSkip:
+		return "skip"
+	}
+	return fmt.Sprintf("GapPolicy(%d)", int(p))
+}
+
+// MarshalText returns the name of the policy.
+func (p GapPolicy) MarshalText() ([]byte, error) {
+	switch p {
+	case GapShift, GapSkip:
+		return []byte(p.String()), nil
+	}
+	return nil, fmt.Errorf("te: invalid gap policy %d", int(p))
+}
+
+// UnmarshalText sets the policy from its name.
+func (p *GapPolicy) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "shift":
+		*p = GapShift
+	case "skip":
+		*p = GapSkip
+	default:
+		return fmt.Errorf("te: invalid gap policy %q", text)
+	}
+	return nil
+}
+
+func (p GapPolicy) GoString() string {
+	switch p {
+	case GapShift:
+		return "te.GapShift"
+	case GapSkip:
+		return "te.GapSkip"
+	}
+	return fmt.Sprintf("te.GapPolicy(%d)", int(p))
+}
+
+// OverlapPolicy determines how wall clock times that occur twice
+// because clocks were set back for daylight saving time are handled.
+type OverlapPolicy int
+
+const (
+	// OverlapFirst uses the first instance of an ambiguous time.
+	// This is the default policy.
+	OverlapFirst OverlapPolicy = iota
+
+	// OverlapSecond uses the second instance of an ambiguous time.
+	OverlapSecond
+
+	// OverlapBoth uses both instances of an ambiguous time.
+	OverlapBoth
+)
+
+func (p OverlapPolicy) String() string {
+	switch p {
+	case OverlapFirst:
+		return "first"
+	case OverlapSecond:
+		return "second"
+	case OverlapBoth:
+		return "both"
+	}
+	return fmt.Sprintf("OverlapPolicy(%d)", int(p))
+}
+
+// MarshalText returns the name of the policy.
+func (p OverlapPolicy) MarshalText() ([]byte, error) {
+	switch p {
+	case OverlapFirst, OverlapSecond, OverlapBoth:
+		return []byte(p.String()), nil
+	}
+	return nil, fmt.Errorf("te: invalid overlap policy %d", int(p))
+}
+
+// UnmarshalText sets the policy from its name.
+func (p *OverlapPolicy) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "first":
+		*p = OverlapFirst
+	case "second":
+		*p = OverlapSecond
+	case "both":
+		*p = OverlapBoth
+	default:
+		return fmt.Errorf("te: invalid overlap policy %q", text)
+	}
+	return nil
+}
+
+func (p OverlapPolicy) GoString() string {
+	switch p {
+	case OverlapFirst:
+		return "te.OverlapFirst"
+	case OverlapSecond:
+		return "te.OverlapSecond"
+	case OverlapBoth:
+		return "te.OverlapBoth"
+	}
+	return fmt.Sprintf("te.OverlapPolicy(%d)", int(p))
+}
+
+// Policy determines how expressions based on the wall clock handle
+// daylight saving time transitions. The zero value is the default
+// policy of shifting nonexistent times and using the first instance
+// of ambiguous times.
+type Policy struct {
+	Gap     GapPolicy
+	Overlap OverlapPolicy
+}
+
+func (p Policy) GoString() string {
+	fields := make([]string, 0, 2)
+	if p.Gap != GapShift {
+		fields = append(fields, fmt.Sprintf("Gap: %#v", p.Gap))
+	}
+	if p.Overlap != OverlapFirst {
+		fields = append(fields, fmt.Sprintf("Overlap: %#v", p.Overlap))
+	}
+	return "te.Policy{" + strings.Join(fields, ", ") + "}"
+}
+
+// format wraps the Go syntax representation of an expression with
+// the policy if it is not the default policy.
+func (p Policy) format(s string) string {
+	if p == (Policy{}) {
+		return s
+	}
+	return fmt.Sprintf("te.WithPolicy(%s, %#v)", s, p)
+}
+
+// isActive reports whether t is an instance of a wall clock time
+// matched by fn according to the policy. The wall clock time passed
+// to fn is represented in UTC as it may not exist in the location of t.
+func (p Policy) isActive(t time.Time, fn func(w time.Time) bool) bool {
+	loc := t.Location()
+	w := wallClock(t)
+	if fn(w) {
+		ts, _ := instants(w, loc)
+		if len(ts) < 2 || p.Overlap == OverlapBoth {
+			return true
+		}
+		i := 0
+		if p.Overlap == OverlapSecond {
+			i = 1
+		}
+		_, o1 := ts[i].Zone()
+		_, o2 := t.Zone()
+		return o1 == o2
+	}
+	if p.Gap == GapShift {
+		_, after := t.Zone()
+		_, before := t.Add(-24 * time.Hour).Zone()
+		if after > before {
+			g := w.Add(-time.Duration(after-before) * time.Second)
+			ts, _ := instants(g, loc)
+			return len(ts) == 0 && fn(g)
+		}
+	}
+	return false
+}
+
+// next returns the earliest instant after t of the wall clock times
+// generated by fn, resolved according to the policy. Given a wall clock
+// time represented in UTC, fn returns the next wall clock time after it.
+// The search ends if fn returns the zero time or a time that is not
+// after the previous one.
+func (p Policy) next(t time.Time, fn func(w time.Time) time.Time) time.Time {
+	loc := t.Location()
+	lo, hi := offsets(t)
+	start := wallClock(t).Add(-time.Duration(hi-lo)*time.Second - 1)
+	var next time.Time
+	for prev, w := start, fn(start); !w.IsZero() && w.After(prev); prev, w = w, fn(w) {
+		if !next.IsZero() {
+			_, hi := offsets(w.Add(-time.Duration(lo) * time.Second).In(loc))
+			if w.Add(-time.Duration(hi) * time.Second).After(next) {
+				return next
+			}
+		}
+		for _, ts := range p.resolve(w, loc) {
+			if ts.After(t) && (next.IsZero() || ts.Before(next)) {
+				next = ts
+			}
+		}
+	}
+	return next
+}
+
+// resolve returns the instants in loc of the wall clock time w
+// according to the policy.
+func (p Policy) resolve(w time.Time, loc *time.Location) []time.Time {
+	ts, before := instants(w, loc)
+	switch len(ts) {
+	case 0:
+		if p.Gap == GapSkip {
+			return nil
+		}
+		t := w.Add(-time.Duration(before) * time.Second).In(loc)
+		return []time.Time{t}
+	case 2:
+		switch p.Overlap {
+		case OverlapFirst:
+			return ts[:1]
+		case OverlapSecond:
+			return ts[1:]
+		}
+	}
+	return ts
+}
+
+// instants returns the instants in loc, in order, at which the wall
+// clock reads w. There are no instants if w was skipped by a daylight
+// saving time transition and two instants if w was repeated. The
+// offset in effect before any nearby transition is also returned.
+func instants(w time.Time, loc *time.Location) ([]time.Time, int) {
+	_, before := w.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := w.Add(24 * time.Hour).In(loc).Zone()
+	offsets := []int{before}
+	if after != before {
+		offsets = append(offsets, after)
+		if after > before {
+			offsets[0], offsets[1] = after, before
+		}
+	}
+	ts := make([]time.Time, 0, 2)
+	for _, offset := range offsets {
+		t := w.Add(-time.Duration(offset) * time.Second).In(loc)
+		if _, o := t.Zone(); o == offset {
+			ts = append(ts, t)
+		}
+	}
+	return ts, before
+}
+
+// offsets returns the smallest and largest offsets of loc in effect
+// within a day of t.
+func offsets(t time.Time) (int, int) {
+	_, lo := t.Add(-24 * time.Hour).Zone()
+	_, hi := t.Add(24 * time.Hour).Zone()
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return lo, hi
+}
+
+// wallClock returns the wall clock time of t represented in UTC.
+func wallClock(t time.Time) time.Time {
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+	return time.Date(year, month, day, hour, min, sec, t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,491 @@
+package te
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPolicyNext(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	}
+	shift := Policy{}
+	skip := Policy{Gap: GapSkip}
+	first := Policy{Overlap: OverlapFirst}
+	second := Policy{Overlap: OverlapSecond}
+	both := Policy{Overlap: OverlapBoth}
+	tests := map[string]struct {
+		loc    string
+		expr   Expression
+		policy Policy
+		t      time.Time
+		next   []time.Time
+	}{
+		// Clocks are set forward from 2am EST to 3am EDT on March 13.
+		"New York gap hour shift": {
+			loc:    "America/New_York",
+			expr:   Hour(2),
+			policy: shift,
+			t:      utc(2016, 3, 13, 5, 0, 0),
+			next:   []time.Time{utc(2016, 3, 13, 7, 0, 0), utc(2016, 3, 14, 6, 0, 0)},
+		},
+		"New York gap hour skip": {
+			loc:    "America/New_York",
+			expr:   Hour(2),
+			policy: skip,
+			t:      utc(2016, 3, 13, 5, 0, 0),
+			next:   []time.Time{utc(2016, 3, 14, 6, 0, 0), utc(2016, 3, 15, 6, 0, 0)},
+		},
+		"New York gap hourly shift": {
+			loc:    "America/New_York",
+			expr:   Hourly(2),
+			policy: shift,
+			t:      utc(2016, 3, 13, 5, 0, 0),
+			next:   []time.Time{utc(2016, 3, 13, 7, 0, 0), utc(2016, 3, 13, 8, 0, 0), utc(2016, 3, 13, 10, 0, 0)},
+		},
+		"New York gap hourly skip": {
+			loc:    "America/New_York",
+			expr:   Hourly(2),
+			policy: skip,
+			t:      utc(2016, 3, 13, 5, 0, 0),
+			next:   []time.Time{utc(2016, 3, 13, 8, 0, 0), utc(2016, 3, 13, 10, 0, 0)},
+		},
+		"New York gap minute shift": {
+			loc:    "America/New_York",
+			expr:   Minute(30),
+			policy: shift,
+			t:      utc(2016, 3, 13, 6, 0, 0),
+			next:   []time.Time{utc(2016, 3, 13, 6, 30, 0), utc(2016, 3, 13, 7, 30, 0), utc(2016, 3, 13, 8, 30, 0)},
+		},
+		"New York gap minutely skip": {
+			loc:    "America/New_York",
+			expr:   Minutely(30),
+			policy: skip,
+			t:      utc(2016, 3, 13, 6, 0, 0),
+			next:   []time.Time{utc(2016, 3, 13, 6, 30, 0), utc(2016, 3, 13, 7, 0, 0), utc(2016, 3, 13, 7, 30, 0)},
+		},
+		"New York gap time shift": {
+			loc:    "America/New_York",
+			expr:   Time(2, 30, 0),
+			policy: shift,
+			t:      utc(2016, 3, 13, 5, 0, 0),
+			next:   []time.Time{utc(2016, 3, 13, 7, 30, 0), utc(2016, 3, 14, 6, 30, 0)},
+		},
+		"New York gap time skip": {
+			loc:    "America/New_York",
+			expr:   Time(2, 30, 0),
+			policy: skip,
+			t:      utc(2016, 3, 13, 5, 0, 0),
+			next:   []time.Time{utc(2016, 3, 14, 6, 30, 0)},
+		},
+		"New York gap time range skip": {
+			loc:    "America/New_York",
+			expr:   TimeRange(2, 15, 0, 4, 0, 0),
+			policy: skip,
+			t:      utc(2016, 3, 13, 5, 0, 0),
+			next:   []time.Time{utc(2016, 3, 14, 6, 15, 0)},
+		},
+		"New York gap time range shift": {
+			loc:    "America/New_York",
+			expr:   TimeRange(2, 15, 0, 4, 0, 0),
+			policy: shift,
+			t:      utc(2016, 3, 13, 5, 0, 0),
+			next:   []time.Time{utc(2016, 3, 13, 7, 15, 0), utc(2016, 3, 14, 6, 15, 0)},
+		},
+
+		// Clocks are set back from 2am EDT to 1am EST on November 6.
+		"New York overlap hour first": {
+			loc:    "America/New_York",
+			expr:   Hour(1),
+			policy: first,
+			t:      utc(2016, 11, 6, 4, 0, 0),
+			next:   []time.Time{utc(2016, 11, 6, 5, 0, 0), utc(2016, 11, 7, 6, 0, 0)},
+		},
+		"New York overlap hour second": {
+			loc:    "America/New_York",
+			expr:   Hour(1),
+			policy: second,
+			t:      utc(2016, 11, 6, 4, 0, 0),
+			next:   []time.Time{utc(2016, 11, 6, 6, 0, 0), utc(2016, 11, 7, 6, 0, 0)},
+		},
+		"New York overlap hour both": {
+			loc:    "America/New_York",
+			expr:   Hour(1),
+			policy: both,
+			t:      utc(2016, 11, 6, 4, 0, 0),
+			next:   []time.Time{utc(2016, 11, 6, 5, 0, 0), utc(2016, 11, 6, 6, 0, 0), utc(2016, 11, 7, 6, 0, 0)},
+		},
+		"New York overlap hourly both": {
+			loc:    "America/New_York",
+			expr:   Hourly(1),
+			policy: both,
+			t:      utc(2016, 11, 6, 4, 0, 0),
+			next:   []time.Time{utc(2016, 11, 6, 5, 0, 0), utc(2016, 11, 6, 6, 0, 0), utc(2016, 11, 6, 7, 0, 0)},
+		},
+		"New York overlap hourly first": {
+			loc:    "America/New_York",
+			expr:   Hourly(1),
+			policy: first,
+			t:      utc(2016, 11, 6, 4, 0, 0),
+			next:   []time.Time{utc(2016, 11, 6, 5, 0, 0), utc(2016, 11, 6, 7, 0, 0)},
+		},
+		"New York overlap minutely first": {
+			loc:    "America/New_York",
+			expr:   Minutely(30),
+			policy: first,
+			t:      utc(2016, 11, 6, 4, 0, 0),
+			next:   []time.Time{utc(2016, 11, 6, 4, 30, 0), utc(2016, 11, 6, 5, 0, 0), utc(2016, 11, 6, 5, 30, 0), utc(2016, 11, 6, 7, 0, 0)},
+		},
+		"New York overlap minutely second": {
+			loc:    "America/New_York",
+			expr:   Minutely(30),
+			policy: second,
+			t:      utc(2016, 11, 6, 4, 0, 0),
+			next:   []time.Time{utc(2016, 11, 6, 4, 30, 0), utc(2016, 11, 6, 6, 0, 0), utc(2016, 11, 6, 6, 30, 0), utc(2016, 11, 6, 7, 0, 0)},
+		},
+		"New York overlap minutely both": {
+			loc:    "America/New_York",
+			expr:   Minutely(30),
+			policy: both,
+			t:      utc(2016, 11, 6, 4, 0, 0),
+			next:   []time.Time{utc(2016, 11, 6, 4, 30, 0), utc(2016, 11, 6, 5, 0, 0), utc(2016, 11, 6, 5, 30, 0), utc(2016, 11, 6, 6, 0, 0), utc(2016, 11, 6, 6, 30, 0), utc(2016, 11, 6, 7, 0, 0)},
+		},
+		"New York overlap minute both": {
+			loc:    "America/New_York",
+			expr:   Minute(45),
+			policy: both,
+			t:      utc(2016, 11, 6, 5, 50, 0),
+			next:   []time.Time{utc(2016, 11, 6, 6, 45, 0), utc(2016, 11, 6, 7, 45, 0)},
+		},
+		"New York overlap minute first": {
+			loc:    "America/New_York",
+			expr:   Minute(45),
+			policy: first,
+			t:      utc(2016, 11, 6, 5, 50, 0),
+			next:   []time.Time{utc(2016, 11, 6, 7, 45, 0)},
+		},
+		"New York overlap time both": {
+			loc:    "America/New_York",
+			expr:   Time(1, 30, 0),
+			policy: both,
+			t:      utc(2016, 11, 6, 4, 0, 0),
+			next:   []time.Time{utc(2016, 11, 6, 5, 30, 0), utc(2016, 11, 6, 6, 30, 0), utc(2016, 11, 7, 6, 30, 0)},
+		},
+		"New York overlap time second": {
+			loc:    "America/New_York",
+			expr:   Time(1, 30, 0),
+			policy: second,
+			t:      utc(2016, 11, 6, 4, 0, 0),
+			next:   []time.Time{utc(2016, 11, 6, 6, 30, 0), utc(2016, 11, 7, 6, 30, 0)},
+		},
+		"New York overlap time range both": {
+			loc:    "America/New_York",
+			expr:   TimeRange(1, 15, 0, 1, 45, 0),
+			policy: both,
+			t:      utc(2016, 11, 6, 4, 0, 0),
+			next:   []time.Time{utc(2016, 11, 6, 5, 15, 0), utc(2016, 11, 6, 6, 15, 0), utc(2016, 11, 7, 6, 15, 0)},
+		},
+		"New York overlap secondly": {
+			loc:    "America/New_York",
+			expr:   Secondly(30),
+			policy: both,
+			t:      utc(2016, 11, 6, 5, 59, 45),
+			next:   []time.Time{utc(2016, 11, 6, 6, 0, 0), utc(2016, 11, 6, 6, 0, 30), utc(2016, 11, 6, 6, 1, 0)},
+		},
+		"New York overlap second": {
+			loc:    "America/New_York",
+			expr:   Second(10),
+			policy: both,
+			t:      utc(2016, 11, 6, 6, 0, 20),
+			next:   []time.Time{utc(2016, 11, 6, 6, 1, 10), utc(2016, 11, 6, 6, 2, 10)},
+		},
+
+		// Clocks are set forward from 1am GMT to 2am BST on March 27
+		// and back from 2am BST to 1am GMT on October 30.
+		"London gap hour shift": {
+			loc:    "Europe/London",
+			expr:   Hour(1),
+			policy: shift,
+			t:      utc(2016, 3, 27, 0, 0, 0),
+			next:   []time.Time{utc(2016, 3, 27, 1, 0, 0), utc(2016, 3, 28, 0, 0, 0)},
+		},
+		"London gap hour skip": {
+			loc:    "Europe/London",
+			expr:   Hour(1),
+			policy: skip,
+			t:      utc(2016, 3, 27, 0, 0, 0),
+			next:   []time.Time{utc(2016, 3, 28, 0, 0, 0), utc(2016, 3, 29, 0, 0, 0)},
+		},
+		"London overlap hour both": {
+			loc:    "Europe/London",
+			expr:   Hour(1),
+			policy: both,
+			t:      utc(2016, 10, 29, 23, 0, 0),
+			next:   []time.Time{utc(2016, 10, 30, 0, 0, 0), utc(2016, 10, 30, 1, 0, 0), utc(2016, 10, 31, 1, 0, 0)},
+		},
+		"London overlap hour second": {
+			loc:    "Europe/London",
+			expr:   Hour(1),
+			policy: second,
+			t:      utc(2016, 10, 29, 23, 0, 0),
+			next:   []time.Time{utc(2016, 10, 30, 1, 0, 0), utc(2016, 10, 31, 1, 0, 0)},
+		},
+
+		// Clocks are set back from 3am AEDT to 2am AEST on April 3
+		// and forward from 2am AEST to 3am AEDT on October 2.
+		"Sydney overlap hour first": {
+			loc:    "Australia/Sydney",
+			expr:   Hour(2),
+			policy: first,
+			t:      utc(2016, 4, 2, 13, 0, 0),
+			next:   []time.Time{utc(2016, 4, 2, 15, 0, 0), utc(2016, 4, 3, 16, 0, 0)},
+		},
+		"Sydney overlap hour second": {
+			loc:    "Australia/Sydney",
+			expr:   Hour(2),
+			policy: second,
+			t:      utc(2016, 4, 2, 13, 0, 0),
+			next:   []time.Time{utc(2016, 4, 2, 16, 0, 0), utc(2016, 4, 3, 16, 0, 0)},
+		},
+		"Sydney gap hour skip": {
+			loc:    "Australia/Sydney",
+			expr:   Hour(2),
+			policy: skip,
+			t:      utc(2016, 10, 1, 14, 0, 0),
+			next:   []time.Time{utc(2016, 10, 2, 15, 0, 0), utc(2016, 10, 3, 15, 0, 0)},
+		},
+		"Sydney gap hour shift": {
+			loc:    "Australia/Sydney",
+			expr:   Hour(2),
+			policy: shift,
+			t:      utc(2016, 10, 1, 14, 0, 0),
+			next:   []time.Time{utc(2016, 10, 1, 16, 0, 0), utc(2016, 10, 2, 15, 0, 0)},
+		},
+
+		// Clocks are set back from 2am to 1:30am on April 3 and
+		// forward from 2am to 2:30am on October 2.
+		"Lord Howe overlap minutely both": {
+			loc:    "Australia/Lord_Howe",
+			expr:   Minutely(30),
+			policy: both,
+			t:      utc(2016, 4, 2, 14, 0, 0),
+			next:   []time.Time{utc(2016, 4, 2, 14, 30, 0), utc(2016, 4, 2, 15, 0, 0), utc(2016, 4, 2, 15, 30, 0)},
+		},
+		"Lord Howe overlap minutely first": {
+			loc:    "Australia/Lord_Howe",
+			expr:   Minutely(30),
+			policy: first,
+			t:      utc(2016, 4, 2, 14, 0, 0),
+			next:   []time.Time{utc(2016, 4, 2, 14, 30, 0), utc(2016, 4, 2, 15, 30, 0), utc(2016, 4, 2, 16, 0, 0)},
+		},
+		"Lord Howe gap minute shift": {
+			loc:    "Australia/Lord_Howe",
+			expr:   Minute(15),
+			policy: shift,
+			t:      utc(2016, 10, 1, 14, 30, 0),
+			next:   []time.Time{utc(2016, 10, 1, 14, 45, 0), utc(2016, 10, 1, 15, 45, 0), utc(2016, 10, 1, 16, 15, 0)},
+		},
+		"Lord Howe gap minute skip": {
+			loc:    "Australia/Lord_Howe",
+			expr:   Minute(15),
+			policy: skip,
+			t:      utc(2016, 10, 1, 14, 30, 0),
+			next:   []time.Time{utc(2016, 10, 1, 14, 45, 0), utc(2016, 10, 1, 16, 15, 0), utc(2016, 10, 1, 17, 15, 0)},
+		},
+	}
+	for name, tt := range tests {
+		loc, err := time.LoadLocation(tt.loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expr := WithPolicy(tt.expr, tt.policy)
+		have := next(expr, tt.t.In(loc), len(tt.next))
+		for i := range tt.next {
+			if !have[i].Equal(tt.next[i]) {
+				t.Errorf("%s\nhave next %v\nwant next %v", name, have, tt.next)
+				break
+			}
+		}
+	}
+}
+
+func TestPolicyIsActive(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := map[string]struct {
+		loc      string
+		expr     Expression
+		policy   Policy
+		t        time.Time
+		isActive bool
+	}{
+		"gap shifted hour": {
+			loc:      "America/New_York",
+			expr:     Hour(2),
+			policy:   Policy{},
+			t:        utc(2016, 3, 13, 7, 30),
+			isActive: true,
+		},
+		"gap actual hour": {
+			loc:      "America/New_York",
+			expr:     Hour(3),
+			policy:   Policy{},
+			t:        utc(2016, 3, 13, 7, 30),
+			isActive: true,
+		},
+		"gap skipped hour": {
+			loc:      "America/New_York",
+			expr:     Hour(2),
+			policy:   Policy{Gap: GapSkip},
+			t:        utc(2016, 3, 13, 7, 30),
+			isActive: false,
+		},
+		"gap after shift": {
+			loc:      "America/New_York",
+			expr:     Hour(2),
+			policy:   Policy{},
+			t:        utc(2016, 3, 13, 8, 30),
+			isActive: false,
+		},
+		"gap shifted time range": {
+			loc:      "America/New_York",
+			expr:     TimeRange(1, 30, 0, 2, 30, 0),
+			policy:   Policy{},
+			t:        utc(2016, 3, 13, 7, 15),
+			isActive: true,
+		},
+		"gap skipped time range": {
+			loc:      "America/New_York",
+			expr:     TimeRange(1, 30, 0, 2, 30, 0),
+			policy:   Policy{Gap: GapSkip},
+			t:        utc(2016, 3, 13, 7, 15),
+			isActive: false,
+		},
+		"overlap first instance first": {
+			loc:      "America/New_York",
+			expr:     Hour(1),
+			policy:   Policy{Overlap: OverlapFirst},
+			t:        utc(2016, 11, 6, 5, 30),
+			isActive: true,
+		},
+		"overlap second instance first": {
+			loc:      "America/New_York",
+			expr:     Hour(1),
+			policy:   Policy{Overlap: OverlapFirst},
+			t:        utc(2016, 11, 6, 6, 30),
+			isActive: false,
+		},
+		"overlap first instance second": {
+			loc:      "America/New_York",
+			expr:     Minutely(15),
+			policy:   Policy{Overlap: OverlapSecond},
+			t:        utc(2016, 11, 6, 5, 30),
+			isActive: false,
+		},
+		"overlap second instance second": {
+			loc:      "America/New_York",
+			expr:     Minutely(15),
+			policy:   Policy{Overlap: OverlapSecond},
+			t:        utc(2016, 11, 6, 6, 30),
+			isActive: true,
+		},
+		"overlap second instance both": {
+			loc:      "Europe/London",
+			expr:     Minute(30),
+			policy:   Policy{Overlap: OverlapBoth},
+			t:        utc(2016, 10, 30, 1, 30),
+			isActive: true,
+		},
+		"unambiguous": {
+			loc:      "Asia/Tokyo",
+			expr:     Hour(1),
+			policy:   Policy{Gap: GapSkip, Overlap: OverlapSecond},
+			t:        utc(2016, 11, 5, 16, 30),
+			isActive: true,
+		},
+	}
+	for name, tt := range tests {
+		loc, err := time.LoadLocation(tt.loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expr := WithPolicy(tt.expr, tt.policy)
+		isActive := expr.IsActive(tt.t.In(loc))
+		if isActive != tt.isActive {
+			t.Errorf("%s\nhave isActive %v\nwant isActive %v", name, isActive, tt.isActive)
+		}
+	}
+}
+
+func TestWithPolicy(t *testing.T) {
+	p := Policy{Gap: GapSkip, Overlap: OverlapBoth}
+	expr := WithPolicy(Intersect(Weekday(time.Monday), Union(Hour(1), Minute(30))), p)
+	have := expr.(fmt.GoStringer).GoString()
+	want := "te.Intersect(te.Weekday(time.Monday), te.Union(" +
+		"te.WithPolicy(te.Hour(1), te.Policy{Gap: te.GapSkip, Overlap: te.OverlapBoth}), " +
+		"te.WithPolicy(te.Minute(30), te.Policy{Gap: te.GapSkip, Overlap: te.OverlapBoth})))"
+	if have != want {
+		t.Errorf("have %s\nwant %s", have, want)
+	}
+	expr = WithPolicy(expr, Policy{})
+	have = expr.(fmt.GoStringer).GoString()
+	want = "te.Intersect(te.Weekday(time.Monday), te.Union(te.Hour(1), te.Minute(30)))"
+	if have != want {
+		t.Errorf("have %s\nwant %s", have, want)
+	}
+}
+
+func TestPolicyNextEnd(t *testing.T) {
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]func(w time.Time) time.Time{
+		"zero": func(w time.Time) time.Time {
+			return time.Time{}
+		},
+		"same": func(w time.Time) time.Time {
+			return w
+		},
+		"backward": func(w time.Time) time.Time {
+			return w.Add(-time.Hour)
+		},
+	}
+	for name, fn := range tests {
+		have := Policy{}.next(now, fn)
+		if !have.IsZero() {
+			t.Errorf("%s\nhave next %v\nwant zero time", name, have)
+		}
+	}
+}
+
+func TestSecondOverlap(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clocks are set back from 2am EDT to 1am EST on November 6, and
+	// 6:30 UTC is the second 1:30am.
+	now := time.Date(2016, 11, 6, 6, 30, 0, 0, time.UTC).In(ny)
+	tests := map[string]struct {
+		expr Expression
+		next []time.Time
+	}{
+		"second": {
+			Second(10),
+			[]time.Time{now.Add(10 * time.Second), now.Add(70 * time.Second)},
+		},
+		"secondly": {
+			Secondly(15),
+			[]time.Time{now.Add(15 * time.Second), now.Add(30 * time.Second)},
+		},
+	}
+	for name, tt := range tests {
+		have := next(tt.expr, now, len(tt.next))
+		for i := range tt.next {
+			if !have[i].Equal(tt.next[i]) {
+				t.Errorf("%s\nhave next %v\nwant next %v", name, have, tt.next)
+				break
+			}
+		}
+	}
+}
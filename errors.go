@@ -0,0 +1,148 @@
+package te
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError describes a problem parsing an expression. The offending
+// text is identified by its position within the input.
+type ParseError struct {
+	Input    string   // input being parsed
+	Offset   int      // byte offset of the offending text
+	Column   int      // column of the offending text, counting runes from 1
+	Token    string   // offending text, empty at the end of input
+	Expected []string // kinds of token that would have been accepted, if known
+	Message  string
+	Err      error // underlying error, such as a *RangeError, if any
+
+	// Suggestion is a likely replacement for a misspelled Token,
+	// such as "wednesday" for "wendesday", if any.
+	Suggestion string
+
+	end int // byte offset of the end of the offending text
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "column %d: %s", e.Column, e.Message)
+	if e.Token == "" {
+		b.WriteString(", at end of input")
+	} else {
+		fmt.Fprintf(&b, ", token: %q", e.Token)
+	}
+	if len(e.Expected) > 0 {
+		fmt.Fprintf(&b, ", expected %s", strings.Join(e.Expected, " or "))
+	}
+	if e.Suggestion != "" {
+		fmt.Fprintf(&b, ", did you mean %q?", e.Suggestion)
+	}
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// locate sets the position dependent fields of the error for input.
+func (e *ParseError) locate(input string) {
+	if e.end > len(input) {
+		e.end = len(input)
+	}
+	if e.Offset > e.end {
+		e.Offset = e.end
+	}
+	e.Input = input
+	e.Column = utf8.RuneCountInString(input[:e.Offset]) + 1
+	e.Token = input[e.Offset:e.end]
+}
+
+// newParseError returns a parse error for the token t. The kinds of
+// token that would have been accepted instead of t may be provided.
+func newParseError(t token, message string, expected ...tokenType) *ParseError {
+	e := &ParseError{Offset: t.pos, Message: message, end: t.pos + len(t.val)}
+	for _, typ := range expected {
+		e.Expected = append(e.Expected, typ.String())
+	}
+	return e
+}
+
+// spanError returns a parse error for the tokens from through to.
+func spanError(from, to token, message string) *ParseError {
+	e := newParseError(from, message)
+	e.end = to.pos + len(to.val)
+	return e
+}
+
+// RangeError describes a constructor argument outside of its valid range.
+type RangeError struct {
+	Func  string // constructor, such as "Hour"
+	Arg   string // argument, such as "hour"
+	Value int
+	Min   int
+	Max   int
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("te.%s: %s %d out of range [%d, %d]", e.Func, e.Arg, e.Value, e.Min, e.Max)
+}
+
+// ArgError describes an invalid constructor argument that is not
+// described by a range.
+type ArgError struct {
+	Func    string // constructor, such as "Union"
+	Arg     string // argument, such as "exprs"
+	Message string
+}
+
+func (e *ArgError) Error() string {
+	return fmt.Sprintf("te.%s: %s %s", e.Func, e.Arg, e.Message)
+}
+
+var errNilExpr = errors.New("te: nil expression")
+
+// Validate reports the first invalid expression within expr, including
+// those nested in compositions. Constructors given invalid arguments
+// return the nil expression, which is never active and has no next
+// active time. The returned error is a *RangeError or *ArgError
+// describing the invalid argument.
+func Validate(expr Expression) error {
+	switch e := expr.(type) {
+	case nil:
+		return errNilExpr
+	case nilExpr:
+		if e.err == nil {
+			return errNilExpr
+		}
+		return e.err
+	case locationExpr:
+		return Validate(e.expr)
+	case unionExpr:
+		return validate(e)
+	case intersectExpr:
+		return validate(e)
+	case exceptExpr:
+		return validate(e)
+	}
+	return nil
+}
+
+func validate(exprs []Expression) error {
+	for _, e := range exprs {
+		err := Validate(e)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func outOfRange(fn, arg string, v, min, max int) Expression {
+	return nilExpr{&RangeError{Func: fn, Arg: arg, Value: v, Min: min, Max: max}}
+}
+
+func invalid(fn, arg, message string) Expression {
+	return nilExpr{&ArgError{Func: fn, Arg: arg, Message: message}}
+}
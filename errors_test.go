@@ -0,0 +1,136 @@
+package te
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		expr Expression
+		want error
+	}{
+		"valid": {
+			Intersect(Hour(9), Weekday(time.Monday)),
+			nil,
+		},
+		"hour": {
+			Hour(25),
+			&RangeError{Func: "Hour", Arg: "hour", Value: 25, Min: 0, Max: 23},
+		},
+		"day": {
+			Day(0),
+			&RangeError{Func: "Day", Arg: "n", Value: 0, Min: 1, Max: 31},
+		},
+		"negative day": {
+			Day(-2),
+			&RangeError{Func: "Day", Arg: "n", Value: -2, Min: 1, Max: 31},
+		},
+		"minutely": {
+			Minutely(60),
+			&RangeError{Func: "Minutely", Arg: "n", Value: 60, Min: 1, Max: 59},
+		},
+		"minute step offset": {
+			MinuteStep(15, 60),
+			&RangeError{Func: "MinuteStep", Arg: "offset", Value: 60, Min: 0, Max: 59},
+		},
+		"month": {
+			Month(13),
+			&RangeError{Func: "Month", Arg: "month", Value: 13, Min: 1, Max: 12},
+		},
+		"time range": {
+			TimeRange(9, 0, 0, 24, 0, 0),
+			&RangeError{Func: "TimeRange", Arg: "h2", Value: 24, Min: 0, Max: 23},
+		},
+		"date": {
+			Date(time.February, 32),
+			&RangeError{Func: "Day", Arg: "n", Value: 32, Min: 1, Max: 31},
+		},
+		"nested": {
+			Union(Hour(9), In(Intersect(Weekday(time.Monday), Second(60)), time.UTC)),
+			&RangeError{Func: "Second", Arg: "sec", Value: 60, Min: 0, Max: 59},
+		},
+		"except": {
+			Intersect(Hour(9), Except(Day(32))),
+			&RangeError{Func: "Day", Arg: "n", Value: 32, Min: 1, Max: 31},
+		},
+		"every": {
+			Every(0, time.Time{}),
+			&ArgError{Func: "Every", Arg: "d", Message: "must be positive"},
+		},
+		"in": {
+			In(Hour(9), nil),
+			&ArgError{Func: "In", Arg: "loc", Message: "must not be nil"},
+		},
+		"empty union": {
+			Union(),
+			&ArgError{Func: "Union", Arg: "exprs", Message: "must not be empty"},
+		},
+		"nil": {
+			nil,
+			errNilExpr,
+		},
+	}
+	for name, tt := range tests {
+		err := Validate(tt.expr)
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%s\nunexpected error %v", name, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.want.Error() {
+			t.Errorf("%s\nhave %v\nwant %v", name, err, tt.want)
+		}
+	}
+}
+
+func TestParseValidate(t *testing.T) {
+	_, err := Parse("daily at 9am and every 90 minutes", time.UTC)
+	var rangeErr *RangeError
+	if !errors.As(err, &rangeErr) {
+		t.Fatalf("have %v\nwant *RangeError", err)
+	}
+	want := "te.MinuteStep: n 90 out of range [1, 59]"
+	if rangeErr.Error() != want {
+		t.Errorf("have %q\nwant %q", rangeErr.Error(), want)
+	}
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("have %v\nwant *ParseError", err)
+	}
+	if parseErr.Offset != 17 || parseErr.Token != "every 90 minutes" {
+		t.Errorf("have offset %d token %q\nwant offset 17 token %q", parseErr.Offset, parseErr.Token, "every 90 minutes")
+	}
+	want = `90 minutes is an hour or longer, add "continuously" to count intervals from the Unix epoch`
+	if parseErr.Message != want {
+		t.Errorf("have message %q\nwant message %q", parseErr.Message, want)
+	}
+}
+
+func TestParseErrorString(t *testing.T) {
+	tests := map[string]struct {
+		err  *ParseError
+		want string
+	}{
+		"token": {
+			&ParseError{Column: 10, Token: "25:00", Message: "invalid time"},
+			`column 10: invalid time, token: "25:00"`,
+		},
+		"end of input": {
+			&ParseError{Column: 7, Message: "expected time", Expected: []string{"colon", "am or pm"}},
+			"column 7: expected time, at end of input, expected colon or am or pm",
+		},
+		"suggestion": {
+			&ParseError{Column: 7, Token: "evry", Message: "unknown word", Suggestion: "every"},
+			`column 7: unknown word, token: "evry", did you mean "every"?`,
+		},
+	}
+	for name, tt := range tests {
+		have := tt.err.Error()
+		if have != tt.want {
+			t.Errorf("%s\nhave %q\nwant %q", name, have, tt.want)
+		}
+	}
+}
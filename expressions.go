@@ -2,6 +2,7 @@ package te
 
 import (
 	"fmt"
+	"math/bits"
 	"sort"
 	"strings"
 	"time"
@@ -13,93 +14,120 @@ type Expression interface {
 	Next(t time.Time) time.Time
 }
 
-type hourExpr int
+type hourExpr struct {
+	hour   int
+	policy Policy
+}
 
 func (expr hourExpr) IsActive(t time.Time) bool {
-	return t.Hour() == int(expr)
+	return expr.policy.isActive(t, func(w time.Time) bool {
+		return w.Hour() == expr.hour
+	})
 }
 
 func (expr hourExpr) Next(t time.Time) time.Time {
-	year, month, day := t.Date()
-	loc := t.Location()
-	next := time.Date(year, month, day, int(expr), 0, 0, 0, loc)
-	if t.Equal(next) || t.After(next) {
-		next = next.AddDate(0, 0, 1)
-	}
-	return next
+	return expr.policy.next(t, func(w time.Time) time.Time {
+		year, month, day := w.Date()
+		next := time.Date(year, month, day, expr.hour, 0, 0, 0, time.UTC)
+		if !next.After(w) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	})
 }
 
 func (expr hourExpr) GoString() string {
-	return fmt.Sprintf("te.Hour(%d)", int(expr))
+	return expr.policy.format(fmt.Sprintf("te.Hour(%d)", expr.hour))
 }
 
 type hourlyExpr struct {
-	n int
-	d time.Duration
+	n      int
+	d      time.Duration
+	offset int
+	policy Policy
 }
 
 func (expr hourlyExpr) IsActive(t time.Time) bool {
-	return t.Hour()%expr.n == 0
+	return expr.policy.isActive(t, func(w time.Time) bool {
+		return isStep(w.Hour(), expr.n, expr.offset)
+	})
 }
 
 func (expr hourlyExpr) Next(t time.Time) time.Time {
-	year, month, day := t.Date()
-	hour := t.Hour()
-	loc := t.Location()
-	next := time.Date(year, month, day, hour+expr.n-(hour%expr.n), 0, 0, 0, loc)
-	if next.Day() != day {
-		next = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
-	}
-	return next
+	return expr.policy.next(t, func(w time.Time) time.Time {
+		year, month, day := w.Date()
+		next := nextStep(w.Hour(), expr.n, expr.offset)
+		if next > 23 {
+			return time.Date(year, month, day+1, expr.offset, 0, 0, 0, time.UTC)
+		}
+		return time.Date(year, month, day, next, 0, 0, 0, time.UTC)
+	})
 }
 
 func (expr hourlyExpr) GoString() string {
-	return fmt.Sprintf("te.Hourly(%d)", expr.n)
+	if expr.offset != 0 {
+		return expr.policy.format(fmt.Sprintf("te.HourStep(%d, %d)", expr.n, expr.offset))
+	}
+	return expr.policy.format(fmt.Sprintf("te.Hourly(%d)", expr.n))
 }
 
-type minuteExpr int
+type minuteExpr struct {
+	min    int
+	policy Policy
+}
 
 func (expr minuteExpr) IsActive(t time.Time) bool {
-	return t.Minute() == int(expr)
+	return expr.policy.isActive(t, func(w time.Time) bool {
+		return w.Minute() == expr.min
+	})
 }
 
 func (expr minuteExpr) Next(t time.Time) time.Time {
-	year, month, day := t.Date()
-	hour := t.Hour()
-	loc := t.Location()
-	next := time.Date(year, month, day, hour, int(expr), 0, 0, loc)
-	if t.Equal(next) || t.After(next) {
-		next = next.Add(time.Hour)
-	}
-	return next
+	return expr.policy.next(t, func(w time.Time) time.Time {
+		year, month, day := w.Date()
+		hour := w.Hour()
+		next := time.Date(year, month, day, hour, expr.min, 0, 0, time.UTC)
+		if !next.After(w) {
+			next = next.Add(time.Hour)
+		}
+		return next
+	})
 }
 
 func (expr minuteExpr) GoString() string {
-	return fmt.Sprintf("te.Minute(%d)", int(expr))
+	return expr.policy.format(fmt.Sprintf("te.Minute(%d)", expr.min))
 }
 
 type minutelyExpr struct {
-	n int
-	d time.Duration
+	n      int
+	d      time.Duration
+	offset int
+	policy Policy
 }
 
 func (expr minutelyExpr) IsActive(t time.Time) bool {
-	return t.Minute()%expr.n == 0
+	return expr.policy.isActive(t, func(w time.Time) bool {
+		return isStep(w.Minute(), expr.n, expr.offset)
+	})
 }
 
 func (expr minutelyExpr) Next(t time.Time) time.Time {
-	year, month, day := t.Date()
-	hour, min, _ := t.Clock()
-	loc := t.Location()
-	next := time.Date(year, month, day, hour, min+expr.n-(min%expr.n), 0, 0, loc)
-	if next.Hour() != hour {
-		next = time.Date(year, month, day, hour+1, 0, 0, 0, loc)
-	}
-	return next
+	return expr.policy.next(t, func(w time.Time) time.Time {
+		year, month, day := w.Date()
+		hour, min, _ := w.Clock()
+		next := nextStep(min, expr.n, expr.offset)
+		if next > 59 {
+			return time.Date(year, month, day, hour+1, expr.offset, 0, 0, time.UTC)
+		}
+		return time.Date(year, month, day, hour, next, 0, 0, time.UTC)
+	})
 }
 
 func (expr minutelyExpr) GoString() string {
-	return fmt.Sprintf("te.Minutely(%d)", expr.n)
+	if expr.offset != 0 {
+		return expr.policy.format(fmt.Sprintf("te.MinuteStep(%d, %d)", expr.n, expr.offset))
+	}
+	return expr.policy.format(fmt.Sprintf("te.Minutely(%d)", expr.n))
 }
 
 type secondExpr int
@@ -109,11 +137,9 @@ func (expr secondExpr) IsActive(t time.Time) bool {
 }
 
 func (expr secondExpr) Next(t time.Time) time.Time {
-	year, month, day := t.Date()
-	hour, min, _ := t.Clock()
-	loc := t.Location()
-	next := time.Date(year, month, day, hour, min, int(expr), 0, loc)
-	if t.Equal(next) || t.After(next) {
+	d := time.Duration(int(expr)-t.Second()) * time.Second
+	next := t.Add(d - time.Duration(t.Nanosecond()))
+	if !next.After(t) {
 		next = next.Add(time.Minute)
 	}
 	return next
@@ -124,29 +150,132 @@ func (expr secondExpr) GoString() string {
 }
 
 type secondlyExpr struct {
-	n int
-	d time.Duration
+	n      int
+	d      time.Duration
+	offset int
 }
 
 func (expr secondlyExpr) IsActive(t time.Time) bool {
-	return t.Second()%expr.n == 0
+	return isStep(t.Second(), expr.n, expr.offset)
 }
 
 func (expr secondlyExpr) Next(t time.Time) time.Time {
-	year, month, day := t.Date()
-	hour, min, sec := t.Clock()
-	loc := t.Location()
-	next := time.Date(year, month, day, hour, min, sec+expr.n-(sec%expr.n), 0, loc)
-	if next.Minute() != min {
-		next = time.Date(year, month, day, hour, min+1, 0, 0, loc)
+	sec := t.Second()
+	next := nextStep(sec, expr.n, expr.offset)
+	if next > 59 {
+		next = 60 + expr.offset
 	}
-	return next
+	d := time.Duration(next-sec) * time.Second
+	return t.Add(d - time.Duration(t.Nanosecond()))
 }
 
 func (expr secondlyExpr) GoString() string {
+	if expr.offset != 0 {
+		return fmt.Sprintf("te.SecondStep(%d, %d)", expr.n, expr.offset)
+	}
 	return fmt.Sprintf("te.Secondly(%d)", expr.n)
 }
 
+// isStep reports whether v is a step of n beginning at offset.
+func isStep(v, n, offset int) bool {
+	return v >= offset && (v-offset)%n == 0
+}
+
+// nextStep returns the next step of n beginning at offset after v.
+// The result may exceed the range of the unit.
+func nextStep(v, n, offset int) int {
+	if v < offset {
+		return offset
+	}
+	return v + n - (v-offset)%n
+}
+
+type millisecondExpr int
+
+func (expr millisecondExpr) IsActive(t time.Time) bool {
+	return t.Nanosecond()/int(time.Millisecond) == int(expr)
+}
+
+func (expr millisecondExpr) Next(t time.Time) time.Time {
+	d := time.Duration(expr)*time.Millisecond - time.Duration(t.Nanosecond())
+	next := t.Add(d)
+	if !next.After(t) {
+		next = next.Add(time.Second)
+	}
+	return next
+}
+
+func (expr millisecondExpr) GoString() string {
+	return fmt.Sprintf("te.Millisecond(%d)", int(expr))
+}
+
+type millisecondlyExpr struct {
+	n int
+	d time.Duration
+}
+
+func (expr millisecondlyExpr) IsActive(t time.Time) bool {
+	return t.Nanosecond()/int(time.Millisecond)%expr.n == 0
+}
+
+func (expr millisecondlyExpr) Next(t time.Time) time.Time {
+	ms := t.Nanosecond() / int(time.Millisecond)
+	next := ms + expr.n - (ms % expr.n)
+	if next > 1000 {
+		next = 1000
+	}
+	d := time.Duration(next)*time.Millisecond - time.Duration(t.Nanosecond())
+	return t.Add(d)
+}
+
+func (expr millisecondlyExpr) GoString() string {
+	return fmt.Sprintf("te.Millisecondly(%d)", expr.n)
+}
+
+type microsecondExpr int
+
+func (expr microsecondExpr) IsActive(t time.Time) bool {
+	return t.Nanosecond()/int(time.Microsecond)%1000 == int(expr)
+}
+
+func (expr microsecondExpr) Next(t time.Time) time.Time {
+	ns := t.Nanosecond() % int(time.Millisecond)
+	d := time.Duration(expr)*time.Microsecond - time.Duration(ns)
+	next := t.Add(d)
+	if !next.After(t) {
+		next = next.Add(time.Millisecond)
+	}
+	return next
+}
+
+func (expr microsecondExpr) GoString() string {
+	return fmt.Sprintf("te.Microsecond(%d)", int(expr))
+}
+
+type microsecondlyExpr struct {
+	n int
+	d time.Duration
+}
+
+func (expr microsecondlyExpr) IsActive(t time.Time) bool {
+	return t.Nanosecond()/int(time.Microsecond)%1000%expr.n == 0
+}
+
+func (expr microsecondlyExpr) Next(t time.Time) time.Time {
+	ns := t.Nanosecond() % int(time.Millisecond)
+	us := ns / int(time.Microsecond)
+	next := us + expr.n - (us % expr.n)
+	if next > 1000 {
+		next = 1000
+	}
+	d := time.Duration(next)*time.Microsecond - time.Duration(ns)
+	return t.Add(d)
+}
+
+func (expr microsecondlyExpr) GoString() string {
+	return fmt.Sprintf("te.Microsecondly(%d)", expr.n)
+}
+
 type dayExpr int
 
 func (expr dayExpr) IsActive(t time.Time) bool {
@@ -236,7 +365,10 @@ func (expr yearExpr) IsActive(t time.Time) bool {
 }
 
 func (expr yearExpr) Next(t time.Time) time.Time {
-	return time.Time{}
+	if t.Year() >= int(expr) {
+		return time.Time{}
+	}
+	return time.Date(int(expr), time.January, 1, 0, 0, 0, 0, t.Location())
 }
 
 func (expr yearExpr) GoString() string {
@@ -269,28 +401,158 @@ func (expr dateRangeExpr) GoString() string {
 }
 
 type timeRangeExpr struct {
-	t1 time.Time
-	t2 time.Time
+	t1     time.Time
+	t2     time.Time
+	policy Policy
 }
 
 func (expr timeRangeExpr) IsActive(t time.Time) bool {
-	t1 := timeFrom(t, expr.t1)
-	t2 := timeFrom(t, expr.t2)
-	return isBetween(t, t1, t2)
+	return expr.policy.isActive(t, func(w time.Time) bool {
+		t1 := timeFrom(w, expr.t1)
+		t2 := timeFrom(w, expr.t2)
+		if expr.t2.Before(expr.t1) {
+			return !w.Before(t1) || !w.After(t2)
+		}
+		return isBetween(w, t1, t2)
+	})
 }
 
 func (expr timeRangeExpr) Next(t time.Time) time.Time {
-	next := timeFrom(t, expr.t1)
-	if t.Equal(next) || t.After(next) {
-		next = next.AddDate(0, 0, 1)
-	}
-	return next
+	return expr.policy.next(t, func(w time.Time) time.Time {
+		next := timeFrom(w, expr.t1)
+		if !next.After(w) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	})
 }
 
 func (expr timeRangeExpr) GoString() string {
-	return fmt.Sprintf("te.TimeRange(%d, %d, %d, %d, %d, %d)",
+	return expr.policy.format(fmt.Sprintf("te.TimeRange(%d, %d, %d, %d, %d, %d)",
 		expr.t1.Hour(), expr.t1.Minute(), expr.t1.Second(),
-		expr.t2.Hour(), expr.t2.Minute(), expr.t2.Second())
+		expr.t2.Hour(), expr.t2.Minute(), expr.t2.Second()))
+}
+
+type everyExpr struct {
+	d      time.Duration
+	anchor time.Time
+}
+
+func (expr everyExpr) IsActive(t time.Time) bool {
+	return expr.elapsed(t) < resolution(expr.d)
+}
+
+func (expr everyExpr) Next(t time.Time) time.Time {
+	return t.Add(expr.d - expr.elapsed(t))
+}
+
+// elapsed returns the time elapsed at t since the last occurrence, in
+// [0, d). It is computed from the seconds and nanoseconds between the
+// anchor and t, as the time.Duration between them saturates after about
+// 292 years.
+func (expr everyExpr) elapsed(t time.Time) time.Duration {
+	sec := t.Unix() - expr.anchor.Unix()
+	nsec := int64(t.Nanosecond() - expr.anchor.Nanosecond())
+	neg := sec < 0 || sec == 0 && nsec < 0
+	if neg {
+		sec, nsec = -sec, -nsec
+	}
+	if nsec < 0 {
+		sec--
+		nsec += int64(time.Second)
+	}
+	hi, lo := bits.Mul64(uint64(sec), uint64(time.Second))
+	lo, carry := bits.Add64(lo, uint64(nsec), 0)
+	r := time.Duration(bits.Rem64(hi+carry, lo, uint64(expr.d)))
+	if neg && r != 0 {
+		r = expr.d - r
+	}
+	return r
+}
+
+func (expr everyExpr) GoString() string {
+	return fmt.Sprintf("te.Every(%s, %s)", formatDuration(expr.d), formatTime(expr.anchor))
+}
+
+// resolution returns the largest unit of time that evenly divides d.
+func resolution(d time.Duration) time.Duration {
+	for _, unit := range units {
+		if d%unit == 0 {
+			return unit
+		}
+	}
+	return time.Nanosecond
+}
+
+var units = []time.Duration{
+	time.Hour,
+	time.Minute,
+	time.Second,
+	time.Millisecond,
+	time.Microsecond,
+}
+
+var unitNames = map[time.Duration]string{
+	time.Hour:        "time.Hour",
+	time.Minute:      "time.Minute",
+	time.Second:      "time.Second",
+	time.Millisecond: "time.Millisecond",
+	time.Microsecond: "time.Microsecond",
+	time.Nanosecond:  "time.Nanosecond",
+}
+
+// formatDuration returns the Go syntax representation of d.
+func formatDuration(d time.Duration) string {
+	unit := resolution(d)
+	if d == unit {
+		return unitNames[unit]
+	}
+	return fmt.Sprintf("%d*%s", d/unit, unitNames[unit])
+}
+
+// formatTime returns the Go syntax representation of t.
+func formatTime(t time.Time) string {
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+	return fmt.Sprintf("time.Date(%d, time.%s, %d, %d, %d, %d, %d, %s)",
+		year, month, day, hour, min, sec, t.Nanosecond(), formatLocation(t.Location()))
+}
+
+// formatLocation returns the Go syntax representation of loc. Fixed
+// zones are written as time.FixedZone and other locations as a function
+// literal that loads them by name.
+func formatLocation(loc *time.Location) string {
+	switch loc {
+	case time.UTC:
+		return "time.UTC"
+	case time.Local:
+		return "time.Local"
+	}
+	if offset, ok := fixedOffset(loc); ok {
+		return fmt.Sprintf("time.FixedZone(%q, %d)", loc, offset)
+	}
+	return fmt.Sprintf("func() *time.Location { loc, _ := time.LoadLocation(%q); return loc }()", loc)
+}
+
+type locationExpr struct {
+	expr Expression
+	loc  *time.Location
+}
+
+func (expr locationExpr) IsActive(t time.Time) bool {
+	return expr.expr.IsActive(t.In(expr.loc))
+}
+
+func (expr locationExpr) Next(t time.Time) time.Time {
+	next := expr.expr.Next(t.In(expr.loc))
+	if next.IsZero() {
+		return time.Time{}
+	}
+	return next.In(t.Location())
+}
+
+func (expr locationExpr) GoString() string {
+	return fmt.Sprintf("te.In(%#v, %s)", expr.expr, formatLocation(expr.loc))
 }
 
 type unionExpr []Expression
@@ -328,24 +590,39 @@ func (expr intersectExpr) IsActive(t time.Time) bool {
 	return true
 }
 
+// intersectSteps is the number of candidate times Next checks for one at
+// which all expressions of an intersection are active. Each candidate is
+// the earliest next time of the expressions, so an intersection that is
+// never active, such as Monday and Tuesday or every minute except every
+// minute, stops after this many steps. The limit allows about a year
+// of minutes.
+const intersectSteps = 1 << 19
+
 func (expr intersectExpr) Next(t time.Time) time.Time {
-	ts := make(byTime, 0)
-	for _, e := range expr {
-		next := e.Next(t)
-		if next.IsZero() {
-			if !e.IsActive(t) {
-				return time.Time{}
+	for i := 0; i < intersectSteps; i++ {
+		var min time.Time
+		for _, e := range expr {
+			next := e.Next(t)
+			if next.IsZero() {
+				if !e.IsActive(t) {
+					return time.Time{}
+				}
+				continue
+			}
+			if min.IsZero() || next.Before(min) {
+				min = next
 			}
-			continue
 		}
-		ts = append(ts, next)
-	}
-	sort.Sort(ts)
-	t = ts[0]
-	if expr.IsActive(t) {
-		return t
+		// Every expression is active but none occurs again.
+		if min.IsZero() {
+			return time.Time{}
+		}
+		t = min
+		if expr.IsActive(t) {
+			return t
+		}
 	}
-	return expr.Next(t)
+	return time.Time{}
 }
 
 func (expr intersectExpr) GoString() string {
@@ -376,7 +653,11 @@ func (expr exceptExpr) GoString() string {
 	return formatExpr("Except", expr)
 }
 
-type nilExpr struct{}
+// nilExpr is never active. It is returned by constructors given invalid
+// arguments, in which case err describes the problem.
+type nilExpr struct {
+	err error
+}
 
 func (expr nilExpr) IsActive(t time.Time) bool  { return false }
 func (expr nilExpr) Next(t time.Time) time.Time { return time.Time{} }
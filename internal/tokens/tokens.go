@@ -0,0 +1,15 @@
+// Package tokens shares the tokens read by the te parser with the te
+// command without making the lexer part of the te API.
+package tokens
+
+// Token is a word, number or symbol of an expression as read by te.Parse.
+type Token struct {
+	Type   string // kind of token, such as "weekday" or "digit"
+	Value  string // text of the token, in lower case except for zones
+	Offset int    // byte offset of the token within the input
+}
+
+// Lex returns the tokens of s in the order they are read by te.Parse.
+// The error is a *te.ParseError if s contains text that cannot be read.
+// It is set by package te, which must be imported for it to be non-nil.
+var Lex func(s string) ([]Token, error)
@@ -0,0 +1,427 @@
+package te
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JSONVersion is the version of the JSON representation of expressions
+// written by MarshalJSON. UnmarshalJSON rejects documents of other
+// versions.
+const JSONVersion = 1
+
+// MarshalJSON returns the JSON representation of expr. The document is
+// an object holding the version and the expression:
+//
+//	{"version":1,"expr":{"intersect":[{"weekday":"tue"},{"hour":4}]}}
+//
+// Each expression is an object with a single key naming its kind and
+// a value holding its arguments:
+//
+//	{"hour":4}                             Hour(4)
+//	{"hourStep":{"n":3,"offset":1}}        HourStep(3, 1), Hourly(3) has offset 0
+//	{"minute":30}                          Minute(30)
+//	{"minuteStep":{"n":15,"offset":5}}     MinuteStep(15, 5)
+//	{"second":0}                           Second(0)
+//	{"secondStep":{"n":20,"offset":10}}    SecondStep(20, 10)
+//	{"millisecond":500}                    Millisecond(500)
+//	{"millisecondly":250}                  Millisecondly(250)
+//	{"microsecond":500}                    Microsecond(500)
+//	{"microsecondly":250}                  Microsecondly(250)
+//	{"every":{"d":"1h30m0s","anchor":"2016-01-01T00:00:00Z"}}
+//	{"day":-1}                             Day(-1)
+//	{"daily":{}}                           Daily()
+//	{"weekday":"tue"}                      Weekday(time.Tuesday)
+//	{"month":"jan"}                        Month(time.January)
+//	{"year":2026}                          Year(2026)
+//	{"dateRange":{"from":"01-02","to":"02-14"}}
+//	{"timeRange":{"from":"09:00:00","to":"17:30:00"}}
+//	{"in":{"location":"Europe/London","expr":{...}}}
+//	{"union":[{...},{...}]}
+//	{"intersect":[{...},{...}]}
+//	{"except":[{...}]}
+//	{"withPolicy":{"gap":"skip","overlap":"both","expr":{...}}}
+//
+// Durations are formatted by time.Duration.String and anchors as
+// RFC 3339 times. Locations are written by name, fixed zones by their
+// offset such as -05:00, and read as time zone qualifiers are by Parse.
+// Expressions with a daylight saving time policy other than the default
+// are wrapped with withPolicy.
+//
+// An error is returned for invalid expressions and for expressions
+// implemented outside of this package.
+func MarshalJSON(expr Expression) ([]byte, error) {
+	v, err := marshalExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Version int         `json:"version"`
+		Expr    interface{} `json:"expr"`
+	}{JSONVersion, v})
+}
+
+// UnmarshalJSON returns the expression represented by data, as written
+// by MarshalJSON. A bare expression without the enclosing document is
+// also accepted. Invalid arguments are reported as by Validate.
+func UnmarshalJSON(data []byte) (Expression, error) {
+	var doc map[string]json.RawMessage
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := doc["version"]
+	if !ok {
+		return unmarshalNode(doc)
+	}
+	var version int
+	err = json.Unmarshal(v, &version)
+	if err != nil {
+		return nil, fmt.Errorf("te: invalid version: %w", err)
+	}
+	if version != JSONVersion {
+		return nil, fmt.Errorf("te: unsupported version %d", version)
+	}
+	e, ok := doc["expr"]
+	if !ok {
+		return nil, errors.New("te: missing expr")
+	}
+	return unmarshalExpr(e)
+}
+
+type stepJSON struct {
+	N      int `json:"n"`
+	Offset int `json:"offset"`
+}
+
+type everyJSON struct {
+	D      string    `json:"d"`
+	Anchor time.Time `json:"anchor"`
+}
+
+type rangeJSON struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type inJSON struct {
+	Location string          `json:"location"`
+	Expr     json.RawMessage `json:"expr"`
+}
+
+type policyJSON struct {
+	Gap     GapPolicy       `json:"gap"`
+	Overlap OverlapPolicy   `json:"overlap"`
+	Expr    json.RawMessage `json:"expr"`
+}
+
+const (
+	dateRangeLayout = "01-02"
+	timeRangeLayout = "15:04:05"
+)
+
+func marshalExpr(expr Expression) (interface{}, error) {
+	n := Inspect(expr)
+	v, err := marshalNode(n)
+	if err != nil {
+		return nil, err
+	}
+	if n.Policy != (Policy{}) {
+		v = map[string]interface{}{"withPolicy": map[string]interface{}{
+			"gap":     n.Policy.Gap,
+			"overlap": n.Policy.Overlap,
+			"expr":    v,
+		}}
+	}
+	return v, nil
+}
+
+// locationName returns the name of loc as written by MarshalJSON. Fixed
+// zones are written as their offset so that names such as EST are not
+// read as an abbreviation of another location.
+func locationName(loc *time.Location) string {
+	if loc == time.UTC {
+		return loc.String()
+	}
+	if offset, ok := fixedOffset(loc); ok {
+		return formatOffset(offset)
+	}
+	return loc.String()
+}
+
+func marshalNode(n Node) (interface{}, error) {
+	var v interface{}
+	switch n.Kind {
+	case KindNil:
+		err := n.Err
+		if err == nil {
+			err = errNilExpr
+		}
+		return nil, err
+	case KindOther:
+		return nil, errors.New("te: unsupported expression")
+	case KindHourStep, KindMinuteStep, KindSecondStep:
+		v = stepJSON{N: n.Args[0].(int), Offset: n.Args[1].(int)}
+	case KindEvery:
+		v = everyJSON{D: n.Args[0].(time.Duration).String(), Anchor: n.Args[1].(time.Time)}
+	case KindDaily:
+		v = struct{}{}
+	case KindWeekday:
+		v = formatWeekday(n.Args[0].(time.Weekday))
+	case KindMonth:
+		v = formatMonth(n.Args[0].(time.Month))
+	case KindDateRange:
+		t1 := time.Date(1, n.Args[0].(time.Month), n.Args[1].(int), 0, 0, 0, 0, time.UTC)
+		t2 := time.Date(1, n.Args[2].(time.Month), n.Args[3].(int), 0, 0, 0, 0, time.UTC)
+		v = rangeJSON{From: t1.Format(dateRangeLayout), To: t2.Format(dateRangeLayout)}
+	case KindTimeRange:
+		t1 := time.Date(1, 1, 1, n.Args[0].(int), n.Args[1].(int), n.Args[2].(int), 0, time.UTC)
+		t2 := time.Date(1, 1, 1, n.Args[3].(int), n.Args[4].(int), n.Args[5].(int), 0, time.UTC)
+		v = rangeJSON{From: t1.Format(timeRangeLayout), To: t2.Format(timeRangeLayout)}
+	case KindIn:
+		e, err := marshalExpr(n.Children[0])
+		if err != nil {
+			return nil, err
+		}
+		v = map[string]interface{}{
+			"location": locationName(n.Args[0].(*time.Location)),
+			"expr":     e,
+		}
+	case KindUnion, KindIntersect, KindExcept:
+		exprs := make([]interface{}, len(n.Children))
+		for i, child := range n.Children {
+			e, err := marshalExpr(child)
+			if err != nil {
+				return nil, err
+			}
+			exprs[i] = e
+		}
+		v = exprs
+	default:
+		v = n.Args[0]
+	}
+	return map[string]interface{}{jsonKey(n.Kind): v}, nil
+}
+
+func unmarshalExpr(data []byte) (Expression, error) {
+	var node map[string]json.RawMessage
+	err := json.Unmarshal(data, &node)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalNode(node)
+}
+
+func unmarshalNode(node map[string]json.RawMessage) (Expression, error) {
+	if len(node) != 1 {
+		return nil, fmt.Errorf("te: expression must have exactly one key, have %d", len(node))
+	}
+	var key string
+	var data json.RawMessage
+	for key, data = range node {
+	}
+	expr, err := unmarshalKind(key, data)
+	if err != nil {
+		return nil, err
+	}
+	err = Validate(expr)
+	if err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+func unmarshalKind(key string, data []byte) (Expression, error) {
+	switch key {
+	case "hour", "minute", "second", "millisecond", "millisecondly",
+		"microsecond", "microsecondly", "day", "year":
+		var n int
+		err := json.Unmarshal(data, &n)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid %s: %w", key, err)
+		}
+		switch key {
+		case "hour":
+			return Hour(n), nil
+		case "minute":
+			return Minute(n), nil
+		case "second":
+			return Second(n), nil
+		case "millisecond":
+			return Millisecond(n), nil
+		case "millisecondly":
+			return Millisecondly(n), nil
+		case "microsecond":
+			return Microsecond(n), nil
+		case "microsecondly":
+			return Microsecondly(n), nil
+		case "day":
+			return Day(n), nil
+		}
+		return Year(n), nil
+	case "hourStep", "minuteStep", "secondStep":
+		var v stepJSON
+		err := json.Unmarshal(data, &v)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid %s: %w", key, err)
+		}
+		switch key {
+		case "hourStep":
+			return HourStep(v.N, v.Offset), nil
+		case "minuteStep":
+			return MinuteStep(v.N, v.Offset), nil
+		}
+		return SecondStep(v.N, v.Offset), nil
+	case "every":
+		var v everyJSON
+		err := json.Unmarshal(data, &v)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid every: %w", err)
+		}
+		d, err := time.ParseDuration(v.D)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid every: %w", err)
+		}
+		return Every(d, v.Anchor), nil
+	case "daily":
+		return Daily(), nil
+	case "weekday":
+		var s string
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid weekday: %w", err)
+		}
+		d, ok := lookupWeekday(s)
+		if !ok {
+			return nil, fmt.Errorf("te: invalid weekday %q", s)
+		}
+		return Weekday(d), nil
+	case "month":
+		var s string
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid month: %w", err)
+		}
+		m, ok := lookupMonth(s)
+		if !ok {
+			return nil, fmt.Errorf("te: invalid month %q", s)
+		}
+		return Month(m), nil
+	case "dateRange", "timeRange":
+		var v rangeJSON
+		err := json.Unmarshal(data, &v)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid %s: %w", key, err)
+		}
+		layout := timeRangeLayout
+		if key == "dateRange" {
+			layout = dateRangeLayout
+		}
+		t1, err := time.Parse(layout, v.From)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid %s: %w", key, err)
+		}
+		t2, err := time.Parse(layout, v.To)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid %s: %w", key, err)
+		}
+		if key == "dateRange" {
+			return DateRange(t1.Month(), t1.Day(), t2.Month(), t2.Day()), nil
+		}
+		return TimeRange(t1.Hour(), t1.Minute(), t1.Second(), t2.Hour(), t2.Minute(), t2.Second()), nil
+	case "in":
+		var v inJSON
+		err := json.Unmarshal(data, &v)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid in: %w", err)
+		}
+		loc, err := loadLocation(v.Location, abbreviations)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid in: %w", err)
+		}
+		expr, err := unmarshalExpr(v.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return In(expr, loc), nil
+	case "union", "intersect", "except":
+		var vs []json.RawMessage
+		err := json.Unmarshal(data, &vs)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid %s: %w", key, err)
+		}
+		exprs := make([]Expression, len(vs))
+		for i, v := range vs {
+			exprs[i], err = unmarshalExpr(v)
+			if err != nil {
+				return nil, err
+			}
+		}
+		switch key {
+		case "union":
+			return Union(exprs...), nil
+		case "intersect":
+			return Intersect(exprs...), nil
+		}
+		return Except(exprs...), nil
+	case "withPolicy":
+		var v policyJSON
+		err := json.Unmarshal(data, &v)
+		if err != nil {
+			return nil, fmt.Errorf("te: invalid withPolicy: %w", err)
+		}
+		expr, err := unmarshalExpr(v.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return WithPolicy(expr, Policy{Gap: v.Gap, Overlap: v.Overlap}), nil
+	}
+	return nil, fmt.Errorf("te: unknown expression %q", key)
+}
+
+// jsonKey returns the key of an expression of kind k.
+func jsonKey(k Kind) string {
+	s := k.String()
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func formatWeekday(d time.Weekday) string {
+	return strings.ToLower(d.String()[:3])
+}
+
+func formatMonth(m time.Month) string {
+	return strings.ToLower(m.String()[:3])
+}
+
+// lookupWeekday returns the weekday named by s, as written by the
+// lexer. Full names and three letter abbreviations are accepted.
+func lookupWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	if keywords[s] != tokenWeekday {
+		return 0, false
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if formatWeekday(d) == s[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// lookupMonth returns the month named by s. Full names and three letter
+// abbreviations are accepted.
+func lookupMonth(s string) (time.Month, bool) {
+	s = strings.ToLower(s)
+	if keywords[s] != tokenMonth {
+		return 0, false
+	}
+	for m := time.January; m <= time.December; m++ {
+		if formatMonth(m) == s[:3] {
+			return m, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,168 @@
+package te
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	for name, tt := range goStringTests {
+		data, err := MarshalJSON(tt.expr)
+		if err != nil {
+			t.Errorf("%s\nunexpected error %v", name, err)
+			continue
+		}
+		expr, err := UnmarshalJSON(data)
+		if err != nil {
+			t.Errorf("%s\nunexpected error %v\n%s", name, err, data)
+			continue
+		}
+		have := fmt.Sprintf("%#v", expr)
+		if have != tt.want {
+			t.Errorf("%s\nhave %s\nwant %s\n%s", name, have, tt.want, data)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	tests := map[string]struct {
+		expr Expression
+		want string
+	}{
+		"intersect": {
+			Intersect(Weekday(time.Tuesday), Hour(4)),
+			`{"version":1,"expr":{"intersect":[{"weekday":"tue"},{"hour":4}]}}`,
+		},
+		"hourly": {
+			Hourly(3),
+			`{"version":1,"expr":{"hourStep":{"n":3,"offset":0}}}`,
+		},
+		"every": {
+			Every(90*time.Minute, time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)),
+			`{"version":1,"expr":{"every":{"d":"1h30m0s","anchor":"2016-01-01T00:00:00Z"}}}`,
+		},
+		"range": {
+			TimeRange(9, 0, 0, 17, 30, 0),
+			`{"version":1,"expr":{"timeRange":{"from":"09:00:00","to":"17:30:00"}}}`,
+		},
+		"policy": {
+			WithPolicy(Hour(1), Policy{Gap: GapSkip}),
+			`{"version":1,"expr":{"withPolicy":{"expr":{"hour":1},"gap":"skip","overlap":"first"}}}`,
+		},
+		"in": {
+			In(Hour(9), mustLoadLocation("Europe/London")),
+			`{"version":1,"expr":{"in":{"expr":{"hour":9},"location":"Europe/London"}}}`,
+		},
+		"in fixed zone": {
+			In(Hour(9), time.FixedZone("EST", -5*60*60)),
+			`{"version":1,"expr":{"in":{"expr":{"hour":9},"location":"-05:00"}}}`,
+		},
+	}
+	for name, tt := range tests {
+		have, err := MarshalJSON(tt.expr)
+		if err != nil {
+			t.Errorf("%s\nunexpected error %v", name, err)
+			continue
+		}
+		if string(have) != tt.want {
+			t.Errorf("%s\nhave %s\nwant %s", name, have, tt.want)
+		}
+	}
+}
+
+func TestJSONFixedZone(t *testing.T) {
+	data, err := MarshalJSON(In(Hour(9), time.FixedZone("EST", -5*60*60)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expr, err := UnmarshalJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Unlike America/New_York, the fixed zone is not in daylight saving
+	// time in July.
+	now := time.Date(2016, 7, 1, 0, 0, 0, 0, time.UTC)
+	have := expr.Next(now)
+	want := time.Date(2016, 7, 1, 14, 0, 0, 0, time.UTC)
+	if !have.Equal(want) {
+		t.Errorf("have next %v\nwant next %v\n%s", have, want, data)
+	}
+}
+
+func TestMarshalJSONError(t *testing.T) {
+	tests := map[string]Expression{
+		"nil":    Hour(25),
+		"nested": Union(Hour(9), Day(0)),
+		"other":  otherExpr{},
+	}
+	for name, expr := range tests {
+		have, err := MarshalJSON(expr)
+		if err == nil {
+			t.Errorf("%s\nhave %s\nwant error", name, have)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		want string
+	}{
+		"bare": {
+			`{"intersect":[{"weekday":"tuesday"},{"hour":4}]}`,
+			"te.Intersect(te.Weekday(time.Tuesday), te.Hour(4))",
+		},
+		"month": {
+			`{"version":1,"expr":{"month":"December"}}`,
+			"te.Month(time.December)",
+		},
+		"in": {
+			`{"in":{"location":"+05:30","expr":{"hour":9}}}`,
+			`te.In(te.Hour(9), time.FixedZone("+05:30", 19800))`,
+		},
+		"policy defaults": {
+			`{"withPolicy":{"overlap":"both","expr":{"minute":30}}}`,
+			"te.WithPolicy(te.Minute(30), te.Policy{Overlap: te.OverlapBoth})",
+		},
+	}
+	for name, tt := range tests {
+		expr, err := UnmarshalJSON([]byte(tt.in))
+		if err != nil {
+			t.Errorf("%s\nunexpected error %v", name, err)
+			continue
+		}
+		have := fmt.Sprintf("%#v", expr)
+		if have != tt.want {
+			t.Errorf("%s\nhave %s\nwant %s", name, have, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalJSONError(t *testing.T) {
+	tests := []string{
+		``,
+		`[]`,
+		`{}`,
+		`{"hour":1,"minute":2}`,
+		`{"version":2,"expr":{"hour":1}}`,
+		`{"version":1}`,
+		`{"hour":25}`,
+		`{"hour":"9"}`,
+		`{"fortnightly":1}`,
+		`{"weekday":"tues"}`,
+		`{"month":"sun"}`,
+		`{"union":[]}`,
+		`{"union":[{"hour":1},{"day":0}]}`,
+		`{"every":{"d":"soon","anchor":"2016-01-01T00:00:00Z"}}`,
+		`{"timeRange":{"from":"9am","to":"17:00:00"}}`,
+		`{"in":{"location":"Mars/Olympus_Mons","expr":{"hour":9}}}`,
+		`{"withPolicy":{"gap":"leap","expr":{"hour":1}}}`,
+	}
+	for _, tt := range tests {
+		have, err := UnmarshalJSON([]byte(tt))
+		if err == nil {
+			t.Errorf("UnmarshalJSON(%s)\nhave %#v\nwant error", tt, have)
+		}
+	}
+}
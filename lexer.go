@@ -1,7 +1,6 @@
 package te
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 	"unicode"
@@ -15,32 +14,44 @@ type lexer struct {
 	i, j   int // position within input
 	width  int // width of last rune
 	tokens []token
+	err    *ParseError
+	abbrs  map[string]string // time zone abbreviations
 }
 
-func lex(s string) ([]token, error) {
+func lex(s string, abbrs map[string]string) ([]token, error) {
 	l := &lexer{
-		input:  strings.ToLower(s),
+		input:  s,
 		tokens: make([]token, 0),
+		abbrs:  abbrs,
 	}
 	for state := readExpr; state != nil; {
 		state = state(l)
 	}
-	if len(l.tokens) > 0 {
-		last := l.tokens[len(l.tokens)-1]
-		if last.typ == tokenError {
-			return nil, errors.New(last.val)
-		}
+	if l.err != nil {
+		l.err.locate(s)
+		return nil, l.err
 	}
 	return l.tokens, nil
 }
 
 func (l *lexer) emit(typ tokenType) {
-	l.tokens = append(l.tokens, token{typ, l.value()})
+	val := l.value()
+	if typ == tokenZone {
+		val = l.input[l.i:l.j]
+	}
+	l.tokens = append(l.tokens, token{typ, val, l.i})
 	l.i = l.j
 }
 
+// errorf stops lexing with an error for the text read since the last
+// token was emitted, or the next character if nothing has been read.
 func (l *lexer) errorf(format string, args ...interface{}) stateFn {
-	l.tokens = append(l.tokens, token{tokenError, fmt.Sprintf(format, args...)})
+	end := l.j
+	if end == l.i && end < len(l.input) {
+		_, width := utf8.DecodeRuneInString(l.input[end:])
+		end += width
+	}
+	l.err = &ParseError{Offset: l.i, Message: fmt.Sprintf(format, args...), end: end}
 	return nil
 }
 
@@ -48,14 +59,17 @@ func (l *lexer) ignore() {
 	l.i = l.j
 }
 
+// read returns the next rune in lower case. Words are case insensitive,
+// and zone names keep their case because they are sliced from the input.
 func (l *lexer) read() rune {
 	if l.j >= len(l.input) {
+		l.width = 0
 		return eof
 	}
 	r, width := utf8.DecodeRuneInString(l.input[l.j:])
 	l.j += width
 	l.width = width
-	return r
+	return unicode.ToLower(r)
 }
 
 func (l *lexer) readFn(fn func(rune) bool) {
@@ -86,7 +100,7 @@ func (l *lexer) unread() {
 }
 
 func (l *lexer) value() string {
-	return l.input[l.i:l.j]
+	return strings.ToLower(l.input[l.i:l.j])
 }
 
 func readAnd(l *lexer) stateFn {
@@ -94,6 +108,8 @@ func readAnd(l *lexer) stateFn {
 	l.emit(tokenAnd)
 	r := l.peek()
 	switch {
+	case r == '(':
+		return readParen
 	case unicode.IsDigit(r):
 		return readDigit
 	case unicode.IsLetter(r):
@@ -104,6 +120,17 @@ func readAnd(l *lexer) stateFn {
 	return l.errorf("invalid character")
 }
 
+func readBut(l *lexer) stateFn {
+	l.readFn(unicode.IsSpace)
+	i := l.j
+	l.readFn(unicode.IsLetter)
+	if strings.ToLower(l.input[i:l.j]) != "not" {
+		return l.errorf("expected but not")
+	}
+	l.emit(tokenExcept)
+	return readNext
+}
+
 func readColon(l *lexer) stateFn {
 	l.read()
 	l.emit(tokenColon)
@@ -114,6 +141,26 @@ func readColon(l *lexer) stateFn {
 	return readDigit
 }
 
+func readDash(l *lexer) stateFn {
+	l.read()
+	l.emit(tokenDash)
+	r := l.peek()
+	if !unicode.IsDigit(r) {
+		return l.errorf("dash must be followed by a digit")
+	}
+	return readDigit
+}
+
+func readDot(l *lexer) stateFn {
+	l.read()
+	l.emit(tokenDot)
+	r := l.peek()
+	if !unicode.IsDigit(r) {
+		return l.errorf("dot must be followed by a digit")
+	}
+	return readDigit
+}
+
 func readDigit(l *lexer) stateFn {
 	l.readFn(unicode.IsDigit)
 	l.emit(tokenDigit)
@@ -125,8 +172,14 @@ func readDigit(l *lexer) stateFn {
 		return readOrdinal
 	case 'a', 'p':
 		return readTwelveHour
+	case 'm', 'u', 'µ':
+		return readLetter
 	case ':':
 		return readColon
+	case '-':
+		return readDash
+	case '.':
+		return readDot
 	}
 	return readNext
 }
@@ -138,6 +191,16 @@ func readExpr(l *lexer) stateFn {
 		return nil
 	case r == '/':
 		return readAnd
+	case r == ';':
+		return readOr
+	case r == '(', r == ')':
+		return readParen
+	case r == '+', r == '-':
+		return readOffset
+	case r == ':':
+		return readColon
+	case r == '.':
+		return readDot
 	case unicode.IsDigit(r):
 		return readDigit
 	case unicode.IsLetter(r):
@@ -148,102 +211,177 @@ func readExpr(l *lexer) stateFn {
 	return l.errorf("invalid character")
 }
 
+// keywords maps the words recognized by the lexer to their token type.
+var keywords = map[string]tokenType{
+	"daily":        tokenDaily,
+	"midnight":     tokenMidnight,
+	"noon":         tokenNoon,
+	"hourly":       tokenHourly,
+	"weekly":       tokenWeekly,
+	"monthly":      tokenMonthly,
+	"quarterly":    tokenQuarterly,
+	"yearly":       tokenYearly,
+	"annually":     tokenYearly,
+	"sun":          tokenWeekday,
+	"sunday":       tokenWeekday,
+	"mon":          tokenWeekday,
+	"monday":       tokenWeekday,
+	"tue":          tokenWeekday,
+	"tuesday":      tokenWeekday,
+	"wed":          tokenWeekday,
+	"wednesday":    tokenWeekday,
+	"thu":          tokenWeekday,
+	"thursday":     tokenWeekday,
+	"fri":          tokenWeekday,
+	"friday":       tokenWeekday,
+	"sat":          tokenWeekday,
+	"saturday":     tokenWeekday,
+	"jan":          tokenMonth,
+	"january":      tokenMonth,
+	"feb":          tokenMonth,
+	"february":     tokenMonth,
+	"mar":          tokenMonth,
+	"march":        tokenMonth,
+	"apr":          tokenMonth,
+	"april":        tokenMonth,
+	"may":          tokenMonth,
+	"jun":          tokenMonth,
+	"june":         tokenMonth,
+	"jul":          tokenMonth,
+	"july":         tokenMonth,
+	"aug":          tokenMonth,
+	"august":       tokenMonth,
+	"sep":          tokenMonth,
+	"september":    tokenMonth,
+	"oct":          tokenMonth,
+	"october":      tokenMonth,
+	"nov":          tokenMonth,
+	"november":     tokenMonth,
+	"dec":          tokenMonth,
+	"december":     tokenMonth,
+	"every":        tokenEvery,
+	"each":         tokenEvery,
+	"microsecond":  tokenUnitMicrosecond,
+	"microseconds": tokenUnitMicrosecond,
+	"us":           tokenUnitMicrosecond,
+	"µs":           tokenUnitMicrosecond,
+	"millisecond":  tokenUnitMillisecond,
+	"milliseconds": tokenUnitMillisecond,
+	"ms":           tokenUnitMillisecond,
+	"second":       tokenUnitSecond,
+	"seconds":      tokenUnitSecond,
+	"minute":       tokenUnitMinute,
+	"minutes":      tokenUnitMinute,
+	"hour":         tokenUnitHour,
+	"hours":        tokenUnitHour,
+	"day":          tokenUnitDay,
+	"days":         tokenUnitDay,
+	"week":         tokenUnitWeek,
+	"weeks":        tokenUnitWeek,
+	"month":        tokenUnitMonth,
+	"months":       tokenUnitMonth,
+	"year":         tokenUnitYear,
+	"years":        tokenUnitYear,
+	"am":           tokenTwelveHour,
+	"pm":           tokenTwelveHour,
+	"at":           tokenAt,
+	"starting":     tokenStarting,
+	"continuously": tokenContinuously,
+	"between":      tokenBetween,
+	"from":         tokenFrom,
+	"to":           tokenTo,
+	"in":           tokenIn,
+	"of":           tokenOf,
+	"on":           tokenOn,
+	"and":          tokenAnd,
+	"or":           tokenOr,
+	"the":          tokenThe,
+	"last":         tokenLast,
+	"except":       tokenExcept,
+	"excluding":    tokenExcept,
+	"not":          tokenExcept,
+}
+
 func readLetter(l *lexer) stateFn {
 	l.readFn(unicode.IsLetter)
 	val := l.value()
-	switch val {
-	case "daily":
-		l.emit(tokenDaily)
-	case "midnight":
-		l.emit(tokenMidnight)
-	case "noon":
-		l.emit(tokenNoon)
-	case "hourly":
-		l.emit(tokenHourly)
-	case "weekly":
-		l.emit(tokenWeekly)
-	case "monthly":
-		l.emit(tokenMonthly)
-	case "quarterly":
-		l.emit(tokenQuarterly)
-	case "yearly", "annually":
-		l.emit(tokenYearly)
-	case "sun", "sunday":
-		fallthrough
-	case "mon", "monday":
-		fallthrough
-	case "tue", "tuesday":
-		fallthrough
-	case "wed", "wednesday":
-		fallthrough
-	case "thu", "thursday":
-		fallthrough
-	case "fri", "friday":
-		fallthrough
-	case "sat", "saturday":
-		l.emit(tokenWeekday)
-	case "jan", "january":
-		fallthrough
-	case "feb", "february":
-		fallthrough
-	case "mar", "march":
-		fallthrough
-	case "apr", "april":
-		fallthrough
-	case "may":
-		fallthrough
-	case "jun", "june":
-		fallthrough
-	case "jul", "july":
-		fallthrough
-	case "aug", "august":
-		fallthrough
-	case "sep", "september":
-		fallthrough
-	case "oct", "october":
-		fallthrough
-	case "nov", "november":
-		fallthrough
-	case "dec", "december":
-		l.emit(tokenMonth)
-	case "every":
-		l.emit(tokenEvery)
-	case "second", "seconds":
-		l.emit(tokenUnitSecond)
-	case "minute", "minutes":
-		l.emit(tokenUnitMinute)
-	case "hour", "hours":
-		l.emit(tokenUnitHour)
-	case "day", "days":
-		l.emit(tokenUnitDay)
-	case "week", "weeks":
-		l.emit(tokenUnitWeek)
-	case "month", "months":
-		l.emit(tokenUnitMonth)
-	case "year", "years":
-		l.emit(tokenUnitYear)
-	case "am", "pm":
-		l.emit(tokenTwelveHour)
-	case "at":
-		l.emit(tokenAt)
-	case "in":
-		l.emit(tokenIn)
-	case "of":
-		l.emit(tokenOf)
-	case "on":
-		l.emit(tokenOn)
-	case "and":
-		l.emit(tokenAnd)
-	case "the":
-		l.emit(tokenThe)
-	case "last":
-		l.emit(tokenLast)
-	default:
-		return l.errorf("invalid character")
+	if val == "but" {
+		return readBut
+	}
+	if typ, ok := keywords[val]; ok {
+		l.emit(typ)
+		return readNext
 	}
+	r := l.peek()
+	if r == '/' || r == '_' {
+		return readZone
+	}
+	if _, ok := l.abbrs[strings.ToUpper(val)]; !ok {
+		l.errorf("unknown word")
+		l.err.Suggestion = suggest(val)
+		return nil
+	}
+	l.emit(tokenZone)
 	return readNext
 }
 
+// suggest returns the keyword closest to the unknown word val, or the
+// empty string if no keyword is close enough to be a likely misspelling.
+func suggest(val string) string {
+	n := utf8.RuneCountInString(val)
+	if n < 3 {
+		return ""
+	}
+	best := 1 // greatest distance allowed, then distance of rv
+	if n > 4 {
+		best = 2
+	}
+	var rv string
+	for k := range keywords {
+		d := distance(val, k)
+		if d > best {
+			continue
+		}
+		if rv == "" || d < best || k < rv {
+			rv, best = k, d
+		}
+	}
+	return rv
+}
+
+// distance returns the Levenshtein edit distance between a and b.
+func distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	row := make([]int, len(t)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur := row[j]
+			row[j] = min3(row[j]+1, row[j-1]+1, prev+cost)
+			prev = cur
+		}
+	}
+	return row[len(t)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
 func readNext(l *lexer) stateFn {
 	r := l.peek()
 	switch {
@@ -253,12 +391,36 @@ func readNext(l *lexer) stateFn {
 		return readAnd
 	case r == '/':
 		return readAnd
+	case r == ';':
+		return readOr
+	case r == ')':
+		return readParen
 	case unicode.IsSpace(r):
 		return readSpace
 	}
 	return l.errorf("invalid character")
 }
 
+func readOr(l *lexer) stateFn {
+	l.read()
+	l.emit(tokenOr)
+	return readExpr
+}
+
+func readOffset(l *lexer) stateFn {
+	l.read()
+	if !unicode.IsDigit(l.peek()) {
+		return l.errorf("time zone offset must begin with a digit")
+	}
+	l.readFn(unicode.IsDigit)
+	if l.peek() == ':' {
+		l.read()
+		l.readFn(unicode.IsDigit)
+	}
+	l.emit(tokenZone)
+	return readNext
+}
+
 func readOrdinal(l *lexer) stateFn {
 	var ok bool
 	r := l.read()
@@ -277,6 +439,16 @@ func readOrdinal(l *lexer) stateFn {
 	return readNext
 }
 
+func readParen(l *lexer) stateFn {
+	r := l.read()
+	if r == '(' {
+		l.emit(tokenLeftParen)
+		return readExpr
+	}
+	l.emit(tokenRightParen)
+	return readNext
+}
+
 func readSpace(l *lexer) stateFn {
 	r := l.peek()
 	if r == eof {
@@ -299,3 +471,18 @@ func readTwelveHour(l *lexer) stateFn {
 	l.emit(tokenTwelveHour)
 	return readNext
 }
+
+func readZone(l *lexer) stateFn {
+	l.readFn(isZone)
+	l.emit(tokenZone)
+	return readNext
+}
+
+// isZone reports whether r may appear in a time zone location name.
+func isZone(r rune) bool {
+	switch r {
+	case '/', '_', '-', '+':
+		return true
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
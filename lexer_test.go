@@ -3,6 +3,8 @@ package te
 import (
 	"reflect"
 	"testing"
+
+	"github.com/pnelson/te/internal/tokens"
 )
 
 func TestLexer(t *testing.T) {
@@ -21,170 +23,254 @@ func TestLexer(t *testing.T) {
 		{
 			"daily",
 			[]token{
-				{tokenDaily, "daily"},
+				{tokenDaily, "daily", 0},
+			},
+		},
+		{
+			"at .500 of each second",
+			[]token{
+				{tokenAt, "at", 0},
+				{tokenDot, ".", 3},
+				{tokenDigit, "500", 4},
+				{tokenOf, "of", 8},
+				{tokenEvery, "each", 11},
+				{tokenUnitSecond, "second", 16},
 			},
 		},
 		{
 			"midnight",
 			[]token{
-				{tokenMidnight, "midnight"},
+				{tokenMidnight, "midnight", 0},
 			},
 		},
 		{
 			"noon",
 			[]token{
-				{tokenNoon, "noon"},
+				{tokenNoon, "noon", 0},
 			},
 		},
 		{
 			"hourly",
 			[]token{
-				{tokenHourly, "hourly"},
+				{tokenHourly, "hourly", 0},
 			},
 		},
 		{
 			"weekly",
 			[]token{
-				{tokenWeekly, "weekly"},
+				{tokenWeekly, "weekly", 0},
 			},
 		},
 		{
 			"monthly",
 			[]token{
-				{tokenMonthly, "monthly"},
+				{tokenMonthly, "monthly", 0},
 			},
 		},
 		{
 			"quarterly",
 			[]token{
-				{tokenQuarterly, "quarterly"},
+				{tokenQuarterly, "quarterly", 0},
 			},
 		},
 		{
 			"yearly",
 			[]token{
-				{tokenYearly, "yearly"},
+				{tokenYearly, "yearly", 0},
 			},
 		},
 		{
 			"annually",
 			[]token{
-				{tokenYearly, "annually"},
+				{tokenYearly, "annually", 0},
 			},
 		},
 		{
 			"Sunday",
 			[]token{
-				{tokenWeekday, "sunday"},
+				{tokenWeekday, "sunday", 0},
 			},
 		},
 		{
 			"Wednesday",
 			[]token{
-				{tokenWeekday, "wednesday"},
+				{tokenWeekday, "wednesday", 0},
 			},
 		},
 		{
 			"January",
 			[]token{
-				{tokenMonth, "january"},
+				{tokenMonth, "january", 0},
 			},
 		},
 		{
 			"November",
 			[]token{
-				{tokenMonth, "november"},
+				{tokenMonth, "november", 0},
 			},
 		},
 		{
 			"3",
 			[]token{
-				{tokenDigit, "3"},
+				{tokenDigit, "3", 0},
 			},
 		},
 		{
 			"3am",
 			[]token{
-				{tokenDigit, "3"},
-				{tokenTwelveHour, "am"},
+				{tokenDigit, "3", 0},
+				{tokenTwelveHour, "am", 1},
 			},
 		},
 		{
 			"3pm",
 			[]token{
-				{tokenDigit, "3"},
-				{tokenTwelveHour, "pm"},
+				{tokenDigit, "3", 0},
+				{tokenTwelveHour, "pm", 1},
 			},
 		},
 		{
 			"3 PM",
 			[]token{
-				{tokenDigit, "3"},
-				{tokenTwelveHour, "pm"},
+				{tokenDigit, "3", 0},
+				{tokenTwelveHour, "pm", 2},
+			},
+		},
+		{
+			"3PM",
+			[]token{
+				{tokenDigit, "3", 0},
+				{tokenTwelveHour, "pm", 1},
+			},
+		},
+		{
+			"9Am",
+			[]token{
+				{tokenDigit, "9", 0},
+				{tokenTwelveHour, "am", 1},
+			},
+		},
+		{
+			"1ST",
+			[]token{
+				{tokenDigit, "1", 0},
+				{tokenOrdinal, "st", 1},
 			},
 		},
 		{
 			"1st",
 			[]token{
-				{tokenDigit, "1"},
-				{tokenOrdinal, "st"},
+				{tokenDigit, "1", 0},
+				{tokenOrdinal, "st", 1},
 			},
 		},
 		{
 			"2nd",
 			[]token{
-				{tokenDigit, "2"},
-				{tokenOrdinal, "nd"},
+				{tokenDigit, "2", 0},
+				{tokenOrdinal, "nd", 1},
 			},
 		},
 		{
 			"3rd",
 			[]token{
-				{tokenDigit, "3"},
-				{tokenOrdinal, "rd"},
+				{tokenDigit, "3", 0},
+				{tokenOrdinal, "rd", 1},
 			},
 		},
 		{
 			"4th",
 			[]token{
-				{tokenDigit, "4"},
-				{tokenOrdinal, "th"},
+				{tokenDigit, "4", 0},
+				{tokenOrdinal, "th", 1},
 			},
 		},
 		{
 			"Tue/Thu",
 			[]token{
-				{tokenWeekday, "tue"},
-				{tokenAnd, "/"},
-				{tokenWeekday, "thu"},
+				{tokenWeekday, "tue", 0},
+				{tokenAnd, "/", 3},
+				{tokenWeekday, "thu", 4},
 			},
 		},
 		{
 			"Tue, Wed, and Thu",
 			[]token{
-				{tokenWeekday, "tue"},
-				{tokenAnd, ","},
-				{tokenWeekday, "wed"},
-				{tokenAnd, ","},
-				{tokenAnd, "and"},
-				{tokenWeekday, "thu"},
+				{tokenWeekday, "tue", 0},
+				{tokenAnd, ",", 3},
+				{tokenWeekday, "wed", 5},
+				{tokenAnd, ",", 8},
+				{tokenAnd, "and", 10},
+				{tokenWeekday, "thu", 14},
 			},
 		},
 		{
 			"every last day of the month at",
 			[]token{
-				{tokenEvery, "every"},
-				{tokenLast, "last"},
-				{tokenUnitDay, "day"},
-				{tokenOf, "of"},
-				{tokenThe, "the"},
-				{tokenUnitMonth, "month"},
-				{tokenAt, "at"},
+				{tokenEvery, "every", 0},
+				{tokenLast, "last", 6},
+				{tokenUnitDay, "day", 11},
+				{tokenOf, "of", 15},
+				{tokenThe, "the", 18},
+				{tokenUnitMonth, "month", 22},
+				{tokenAt, "at", 28},
+			},
+		},
+		{
+			"daily except Sunday but  not in June",
+			[]token{
+				{tokenDaily, "daily", 0},
+				{tokenExcept, "except", 6},
+				{tokenWeekday, "sunday", 13},
+				{tokenExcept, "but  not", 20},
+				{tokenIn, "in", 29},
+				{tokenMonth, "june", 32},
+			},
+		},
+		{
+			"(Mon or Fri); (noon)",
+			[]token{
+				{tokenLeftParen, "(", 0},
+				{tokenWeekday, "mon", 1},
+				{tokenOr, "or", 5},
+				{tokenWeekday, "fri", 8},
+				{tokenRightParen, ")", 11},
+				{tokenOr, ";", 12},
+				{tokenLeftParen, "(", 14},
+				{tokenNoon, "noon", 15},
+				{tokenRightParen, ")", 19},
+			},
+		},
+		{
+			"2026-12-25",
+			[]token{
+				{tokenDigit, "2026", 0},
+				{tokenDash, "-", 4},
+				{tokenDigit, "12", 5},
+				{tokenDash, "-", 7},
+				{tokenDigit, "25", 8},
+			},
+		},
+		{
+			"9am America/New_York, 17:00 UTC, 6pm -05:30",
+			[]token{
+				{tokenDigit, "9", 0},
+				{tokenTwelveHour, "am", 1},
+				{tokenZone, "America/New_York", 4},
+				{tokenAnd, ",", 20},
+				{tokenDigit, "17", 22},
+				{tokenColon, ":", 24},
+				{tokenDigit, "00", 25},
+				{tokenZone, "UTC", 28},
+				{tokenAnd, ",", 31},
+				{tokenDigit, "6", 33},
+				{tokenTwelveHour, "pm", 34},
+				{tokenZone, "-05:30", 37},
 			},
 		},
 	}
 	for _, tt := range tests {
-		have, err := lex(tt.in)
+		have, err := lex(tt.in, abbreviations)
 		if err != nil {
 			t.Fatalf("lex(%q) %v", tt.in, err)
 		}
@@ -199,11 +285,60 @@ func TestLexerError(t *testing.T) {
 		"TueThu",
 		"1st2nd",
 		"4am3pm",
+		"but",
+		"but now",
+		"2026-",
+		"2026--12",
+		"9am +",
+		"9am Nowhere",
 	}
 	for _, tt := range tests {
-		have, err := lex(tt)
+		have, err := lex(tt, abbreviations)
 		if err == nil {
 			t.Errorf("lex(%q)\nhave %v\nwant lex error", tt, have)
 		}
 	}
 }
+
+func TestSuggest(t *testing.T) {
+	tests := map[string]string{
+		"wendesday": "wednesday",
+		"evry":      "every",
+		"quartely":  "quarterly",
+		"dialy":     "daily",
+		"mondy":     "monday",
+		"minuts":    "minute",
+		"septembr":  "september",
+		"xyzzy":     "",
+		"nowhere":   "",
+		"ab":        "",
+	}
+	for in, want := range tests {
+		have := suggest(in)
+		if have != want {
+			t.Errorf("suggest(%q)\nhave %q\nwant %q", in, have, want)
+		}
+	}
+}
+
+func TestLexTokens(t *testing.T) {
+	have, err := lexTokens("Every Tue at 9am Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []tokens.Token{
+		{Type: "every", Value: "every", Offset: 0},
+		{Type: "weekday", Value: "tue", Offset: 6},
+		{Type: "at", Value: "at", Offset: 10},
+		{Type: "digit", Value: "9", Offset: 13},
+		{Type: "am or pm", Value: "am", Offset: 14},
+		{Type: "time zone", Value: "Europe/Berlin", Offset: 17},
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v\nwant %v", have, want)
+	}
+	_, err = lexTokens("every tue but")
+	if _, ok := err.(*ParseError); !ok {
+		t.Errorf("have %v\nwant *ParseError", err)
+	}
+}
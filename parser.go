@@ -2,41 +2,123 @@ package te
 
 import (
 	"strconv"
-	"strings"
 	"time"
 )
 
 type parser struct {
-	loc    *time.Location
+	abbrs  map[string]string // time zone abbreviations
+	input  string
 	pos    int
+	start  int // position of the first token of the current clause
 	tokens []token
 	exprs  []Expression
+	terms  []Expression
 	join   bool
+	except bool
+	depth  int
 }
 
 // Parse parses the provided string into an Expression.
+//
+// Adjacent clauses are intersected. Items joined by "and", "," or "/"
+// form a union of those items. A clause introduced by "except" is
+// excluded from the clause that precedes it. Clauses separated by "or"
+// or ";" form a union of their intersections. Parentheses group
+// clauses explicitly. From tightest to loosest binding, the operators
+// are "and", juxtaposition, "except" and "or".
+//
+// A time zone qualifier evaluates the clause that precedes it in that
+// location instead of the location of the given time. Qualifiers may
+// be location names such as America/New_York, fixed offsets such as
+// +05:30, or the abbreviations returned by DefaultAbbreviations. Use a
+// Parser to recognize other abbreviations.
+//
+// Values outside of the range of their expression, such as "every 90
+// minutes", are reported with the error returned by Validate. Steps of
+// hours, minutes or seconds restart at the top of each day, hour or
+// minute. Followed by "continuously", as in "every 90 minutes
+// continuously", they are intervals of elapsed time counted from
+// 00:00 UTC on January 1, 1970, offset by any starting clause.
+//
+// A fraction of a second such as ".500" or "at .500 of each second" is
+// that millisecond of each second. Fractions have up to six digits, and
+// may follow a time with seconds, as in "09:30:15.250".
+//
+// The location loc is not used and the result is not bound to it. Like
+// every expression, it is evaluated in the location of the time given
+// to it. Use In, the Location of a Parser or the Location of a Schedule
+// to evaluate it in a location.
 func Parse(s string, loc *time.Location) (Expression, error) {
-	s = strings.TrimSpace(s)
-	tokens, err := lex(s)
+	return parse(s, abbreviations)
+}
+
+// Parser parses expressions with a configured location and set of time
+// zone abbreviations. The zero value parses as the Parse function does.
+type Parser struct {
+	// Location is the location in which the expressions are evaluated,
+	// as if wrapped with In. If nil, they are evaluated in the location
+	// of the time given to them.
+	Location *time.Location
+
+	// Abbreviations maps upper case time zone abbreviations to the
+	// names of their locations. If nil, the abbreviations returned by
+	// DefaultAbbreviations are recognized.
+	Abbreviations map[string]string
+}
+
+// Parse parses s into an Expression in the syntax described by Parse.
+func (p Parser) Parse(s string) (Expression, error) {
+	abbrs := p.Abbreviations
+	if abbrs == nil {
+		abbrs = abbreviations
+	}
+	expr, err := parse(s, abbrs)
+	if err != nil || p.Location == nil {
+		return expr, err
+	}
+	return In(expr, p.Location), nil
+}
+
+func parse(s string, abbrs map[string]string) (Expression, error) {
+	tokens, err := lex(s, abbrs)
 	if err != nil {
 		return nil, err
 	}
 	p := &parser{
-		loc:    loc,
+		abbrs:  abbrs,
+		input:  s,
 		tokens: tokens,
 		exprs:  make([]Expression, 0),
 	}
 	err = p.parseExpr()
 	if err != nil {
+		if e, ok := err.(*ParseError); ok {
+			e.locate(s)
+		}
 		return nilExpr{}, err
 	}
-	if len(p.exprs) == 1 {
-		return p.exprs[0], nil
+	return p.expr(), nil
+}
+
+// validate returns a parse error spanning the current clause if any
+// of exprs are invalid.
+func (p *parser) validate(exprs ...Expression) error {
+	for _, expr := range exprs {
+		err := Validate(expr)
+		if err != nil {
+			e := spanError(p.token(p.start), p.token(p.pos-1), err.Error())
+			e.Err = err
+			return e
+		}
 	}
-	return Intersect(p.exprs...), nil
+	return nil
 }
 
 func (p *parser) add(expr Expression) error {
+	err := p.validate(expr)
+	if err != nil {
+		return err
+	}
 	if p.join {
 		exprs := make([]Expression, 0)
 		u, ok := p.exprs[len(p.exprs)-1].(unionExpr)
@@ -56,6 +138,21 @@ func (p *parser) add(expr Expression) error {
 	return p.parseExpr()
 }
 
+// addAll adds the intersection of exprs. The expressions are added
+// individually unless they are joined to the previous expression.
+func (p *parser) addAll(exprs ...Expression) error {
+	err := p.validate(exprs...)
+	if err != nil {
+		return err
+	}
+	if p.join {
+		return p.add(Intersect(exprs...))
+	}
+	n := len(exprs) - 1
+	p.exprs = append(p.exprs, exprs[:n]...)
+	return p.add(exprs[n])
+}
+
 func (p *parser) parseAt() error {
 	t := p.next()
 	switch t.typ {
@@ -63,25 +160,29 @@ func (p *parser) parseAt() error {
 		return p.parseMidnight(t)
 	case tokenDigit:
 		return p.parseTime(t)
+	case tokenDot:
+		return p.parseFraction()
 	case tokenNoon:
 		return p.parseNoon()
 	}
-	return newParseError(t, "expected time or time constant")
+	return newParseError(t, "expected time or time constant", tokenDigit, tokenDot, tokenMidnight, tokenNoon)
 }
 
 func (p *parser) parseDaily() error {
+	if p.end() {
+		return p.add(Daily())
+	}
 	t := p.next()
 	switch t.typ {
-	case tokenEOF:
-		p.exprs = append(p.exprs, Daily())
-		return nil
 	case tokenAt:
 		return p.parseAt()
 	}
-	return newParseError(t, "unexpected token")
+	return newParseError(t, "unexpected token", tokenAt)
 }
 
-func (p *parser) parseDigit(d token) error {
+// parseDigit parses the clause beginning with the number d. A four digit
+// number alone is a year if year is set.
+func (p *parser) parseDigit(d token, year bool) error {
 	t := p.next()
 	switch t.typ {
 	case tokenColon:
@@ -91,23 +192,40 @@ func (p *parser) parseDigit(d token) error {
 		return p.parseOrdinal(d)
 	case tokenTwelveHour:
 		return p.parseTwelveHour(d, t)
-	case tokenUnitHour:
-		return p.parseUnitHour(d)
-	case tokenUnitMinute:
-		return p.parseUnitMinute(d)
-	case tokenUnitSecond:
-		return p.parseUnitSecond(d)
+	case tokenUnitHour, tokenUnitMinute, tokenUnitSecond:
+		return p.parseStep(d, t)
+	case tokenUnitMillisecond:
+		return p.parseUnitMillisecond(d)
+	case tokenUnitMicrosecond:
+		return p.parseUnitMicrosecond(d)
+	case tokenDash:
+		return p.parseISODate(d)
+	}
+	if len(d.val) == 4 {
+		switch {
+		case !year:
+			return newParseError(d, "expected number followed by a unit such as minutes")
+		case !isYear(d):
+			return newParseError(d, "expected year or time such as 09:30")
+		}
+		p.backup()
+		return p.parseYear(d)
 	}
-	return newParseError(t, "unexpected token")
+	return newParseError(t, "unexpected token", tokenColon, tokenOrdinal, tokenTwelveHour,
+		tokenUnitHour, tokenUnitMinute, tokenUnitSecond)
 }
 
 func (p *parser) parseEvery() error {
 	t := p.next()
 	switch t.typ {
 	case tokenDigit:
-		return p.parseDigit(t)
+		return p.parseDigit(t, false)
 	case tokenMonth:
 		return p.parseMonth(t)
+	case tokenUnitMicrosecond:
+		return p.parseMicrosecondly()
+	case tokenUnitMillisecond:
+		return p.parseMillisecondly()
 	case tokenUnitSecond:
 		return p.parseSecondly()
 	case tokenUnitMinute:
@@ -125,10 +243,13 @@ func (p *parser) parseEvery() error {
 	case tokenWeekday:
 		return p.parseWeekday(t)
 	}
-	return newParseError(t, "unexpected token")
+	return newParseError(t, "unexpected token", tokenDigit, tokenMonth, tokenWeekday,
+		tokenUnitSecond, tokenUnitMinute, tokenUnitHour, tokenUnitDay, tokenUnitWeek,
+		tokenUnitMonth, tokenUnitYear)
 }
 
 func (p *parser) parseExpr() error {
+	p.start = p.pos
 	t := p.next()
 	switch t.typ {
 	case tokenEOF:
@@ -137,6 +258,8 @@ func (p *parser) parseExpr() error {
 			return newParseError(t, "incomplete expression")
 		case len(p.exprs) == 0:
 			return newParseError(t, "empty expression")
+		case p.depth > 0:
+			return newParseError(t, "expected closing parenthesis")
 		default:
 			return nil
 		}
@@ -145,14 +268,26 @@ func (p *parser) parseExpr() error {
 		return p.parseExpr()
 	case tokenAt:
 		return p.parseAt()
+	case tokenBetween:
+		return p.parseTimeRange(tokenAnd)
 	case tokenDaily:
 		return p.parseDaily()
 	case tokenDigit:
-		return p.parseDigit(t)
+		return p.parseDigit(t, true)
+	case tokenDot:
+		return p.parseFraction()
 	case tokenEvery:
 		return p.parseEvery()
+	case tokenExcept:
+		return p.parseExcept(t)
+	case tokenFrom:
+		return p.parseTimeRange(tokenTo)
 	case tokenHourly:
 		return p.parseHourly()
+	case tokenIn:
+		return p.parseIn()
+	case tokenLeftParen:
+		return p.parseGroup()
 	case tokenMidnight:
 		return p.parseMidnight(t)
 	case tokenMonth:
@@ -163,26 +298,105 @@ func (p *parser) parseExpr() error {
 		return p.parseNoon()
 	case tokenOn:
 		return p.parseOn()
+	case tokenOr:
+		return p.parseOr(t)
 	case tokenQuarterly:
 		return p.parseQuarterly()
+	case tokenRightParen:
+		return p.parseRightParen(t)
 	case tokenWeekly:
 		return p.parseWeekly()
 	case tokenWeekday:
 		return p.parseWeekday(t)
 	case tokenYearly:
 		return p.parseYearly()
+	case tokenZone:
+		return p.parseZone(t)
 	}
 	return newParseError(t, "unexpected expression")
 }
 
+func (p *parser) parseExcept(t token) error {
+	switch {
+	case p.except:
+		p.backup()
+		return nil
+	case p.join:
+		return newParseError(t, "incomplete expression")
+	case len(p.exprs) == 0:
+		return newParseError(t, "expected expression before except")
+	}
+	exprs := p.exprs
+	p.exprs = make([]Expression, 0)
+	p.except = true
+	err := p.parseExpr()
+	p.except = false
+	if err != nil {
+		return err
+	}
+	if len(p.exprs) == 0 {
+		return newParseError(p.peek(), "expected expression after except")
+	}
+	p.exprs = append(exprs, Except(intersect(p.exprs)))
+	return p.parseExpr()
+}
+
+func (p *parser) parseGroup() error {
+	exprs, terms, join, except := p.exprs, p.terms, p.join, p.except
+	p.exprs, p.terms, p.join, p.except = make([]Expression, 0), nil, false, false
+	p.depth++
+	err := p.parseExpr()
+	p.depth--
+	if err != nil {
+		return err
+	}
+	expr := p.expr()
+	p.exprs, p.terms, p.join, p.except = exprs, terms, join, except
+	return p.add(expr)
+}
+
 func (p *parser) parseHourly() error {
+	if p.end() {
+		return p.add(Minute(0))
+	}
+	return newParseError(p.next(), "unexpected token")
+}
+
+func (p *parser) parseIn() error {
 	t := p.next()
 	switch t.typ {
 	case tokenEOF:
-		p.exprs = append(p.exprs, Minute(0))
-		return nil
+		return newParseError(t, "expected month or year", tokenMonth, tokenDigit)
+	case tokenMonth:
+		return p.parseMonth(t)
+	case tokenDigit:
+		if isYear(t) {
+			return p.parseYear(t)
+		}
+	}
+	return newParseError(t, "unexpected token", tokenMonth, tokenDigit)
+}
+
+func (p *parser) parseISODate(y token) error {
+	m := p.next()
+	dash := p.next()
+	d := p.next()
+	if m.typ != tokenDigit || dash.typ != tokenDash || d.typ != tokenDigit {
+		return newParseError(y, "expected date as year-month-day")
+	}
+	t, err := time.Parse("2006-01-02", y.val+"-"+m.val+"-"+d.val)
+	if err != nil {
+		return newParseError(y, "invalid date")
+	}
+	year, month, day := t.Date()
+	return p.addAll(Year(year), Month(month), Day(day))
+}
+
+func (p *parser) parseMicrosecondly() error {
+	if p.end() {
+		return p.add(Microsecondly(1))
 	}
-	return newParseError(t, "unexpected token")
+	return newParseError(p.next(), "unexpected token")
 }
 
 func (p *parser) parseMidnight(t token) error {
@@ -193,14 +407,18 @@ func (p *parser) parseMidnight(t token) error {
 	return p.add(expr)
 }
 
+func (p *parser) parseMillisecondly() error {
+	if p.end() {
+		return p.add(Millisecondly(1))
+	}
+	return newParseError(p.next(), "unexpected token")
+}
+
 func (p *parser) parseMinutely() error {
-	t := p.next()
-	switch t.typ {
-	case tokenEOF:
-		p.exprs = append(p.exprs, Second(0))
-		return nil
+	if p.end() {
+		return p.add(Second(0))
 	}
-	return newParseError(t, "unexpected token")
+	return newParseError(p.next(), "unexpected token")
 }
 
 func (p *parser) parseMonth(t token) error {
@@ -233,18 +451,69 @@ func (p *parser) parseMonth(t token) error {
 	default:
 		return newParseError(t, "invalid month")
 	}
-	expr := Month(m)
-	return p.add(expr)
+	if p.peek().typ != tokenDigit {
+		return p.add(Month(m))
+	}
+	d := p.next()
+	switch p.peek().typ {
+	case tokenColon, tokenDash, tokenTwelveHour, tokenUnitHour, tokenUnitMinute, tokenUnitSecond:
+		p.backup()
+		return p.add(Month(m))
+	}
+	return p.parseDate(m, d)
 }
 
-func (p *parser) parseMonthly() error {
+func (p *parser) parseDate(m time.Month, d token) error {
+	n, err := strconv.Atoi(d.val)
+	if err != nil {
+		return newParseError(d, "invalid number")
+	}
+	if isYear(d) {
+		return p.addAll(Year(n), Month(m))
+	}
+	if p.peek().typ == tokenOrdinal {
+		p.next()
+	}
+	year, ok := p.parseDateYear()
+	if !ok {
+		year = 2000 // leap year
+	}
+	t := time.Date(year, m, n, 0, 0, 0, 0, time.UTC)
+	if t.Month() != m || t.Day() != n {
+		return newParseError(d, "invalid day")
+	}
+	if ok {
+		return p.addAll(Year(year), Month(m), Day(n))
+	}
+	return p.addAll(Month(m), Day(n))
+}
+
+// parseDateYear consumes the optional year following the day of a date.
+func (p *parser) parseDateYear() (int, bool) {
+	pos := p.pos
 	t := p.next()
-	switch t.typ {
-	case tokenEOF:
-		p.exprs = append(p.exprs, Day(1))
-		return nil
+	if t.typ == tokenAnd && t.val == "," {
+		t = p.next()
+	}
+	if isYear(t) {
+		switch p.peek().typ {
+		case tokenColon, tokenDash, tokenOrdinal, tokenTwelveHour:
+		default:
+			year, err := strconv.Atoi(t.val)
+			if err == nil {
+				return year, true
+			}
+		}
+	}
+	p.pos = pos
+	return 0, false
+}
+
+func (p *parser) parseMonthly() error {
+	if p.end() {
+		return p.add(Day(1))
 	}
-	return newParseError(t, "unexpected token")
+	return newParseError(p.next(), "unexpected token")
 }
 
 func (p *parser) parseNoon() error {
@@ -256,26 +525,39 @@ func (p *parser) parseOn() error {
 	t := p.next()
 	switch t.typ {
 	case tokenEOF:
-		return newParseError(t, "expected weekday")
+		return newParseError(t, "expected weekday", tokenWeekday)
 	case tokenWeekday:
 		return p.parseWeekday(t)
 	}
-	return newParseError(t, "unexpected token")
+	return newParseError(t, "unexpected token", tokenWeekday)
+}
+
+func (p *parser) parseOr(t token) error {
+	switch {
+	case p.except:
+		p.backup()
+		return nil
+	case p.join:
+		return newParseError(t, "incomplete expression")
+	case len(p.exprs) == 0:
+		return newParseError(t, "expected expression before or")
+	}
+	p.terms = append(p.terms, intersect(p.exprs))
+	p.exprs = make([]Expression, 0)
+	return p.parseExpr()
 }
 
 func (p *parser) parseOrdinal(d token) error {
 	n, err := strconv.Atoi(d.val)
 	if err != nil {
-		return err
+		return newParseError(d, "invalid number")
 	}
 	expr := Day(n)
 	return p.add(expr)
 }
 
 func (p *parser) parseQuarterly() error {
-	t := p.next()
-	switch t.typ {
-	case tokenEOF:
+	if p.end() {
 		expr := Intersect(
 			Union(
 				Month(time.January),
@@ -285,34 +567,108 @@ func (p *parser) parseQuarterly() error {
 			),
 			Day(1),
 		)
-		p.exprs = append(p.exprs, expr)
+		return p.add(expr)
+	}
+	return newParseError(p.next(), "unexpected token")
+}
+
+func (p *parser) parseRightParen(t token) error {
+	switch {
+	case p.depth == 0:
+		return newParseError(t, "unexpected closing parenthesis")
+	case p.except:
+		p.backup()
 		return nil
+	case p.join:
+		return newParseError(t, "incomplete expression")
+	case len(p.exprs) == 0:
+		return newParseError(t, "empty expression")
 	}
-	return newParseError(t, "unexpected token")
+	return nil
 }
 
 func (p *parser) parseSecondly() error {
-	t := p.next()
-	switch t.typ {
-	case tokenEOF:
-		p.exprs = append(p.exprs, Secondly(1))
-		return nil
+	if p.end() {
+		return p.add(Secondly(1))
 	}
-	return newParseError(t, "unexpected token")
+	return newParseError(p.next(), "unexpected token")
 }
 
 func (p *parser) parseTime(h token) error {
 	t := p.next()
 	switch t.typ {
 	case tokenEOF:
-		return newParseError(t, "expected time")
+		return newParseError(t, "expected time", tokenColon, tokenTwelveHour)
 	case tokenColon:
 		m := p.next()
 		return p.parseTwentyFourHour(h, m)
 	case tokenTwelveHour:
 		return p.parseTwelveHour(h, t)
 	}
-	return newParseError(t, "unexpected token")
+	return newParseError(t, "unexpected token", tokenColon, tokenTwelveHour)
+}
+
+// parseTimeRange parses the times of a clause such as "between 1am and
+// 5am" or "from 09:00 to 17:30", separated by sep. A range that ends
+// before it begins continues past midnight.
+func (p *parser) parseTimeRange(sep tokenType) error {
+	t1, err := p.parseClock()
+	if err != nil {
+		return err
+	}
+	t := p.next()
+	if t.typ != sep {
+		return newParseError(t, "expected "+sep.String(), sep)
+	}
+	t2, err := p.parseClock()
+	if err != nil {
+		return err
+	}
+	h1, m1, s1 := t1.Clock()
+	h2, m2, s2 := t2.Clock()
+	expr := TimeRange(h1, m1, s1, h2, m2, s2)
+	return p.add(expr)
+}
+
+// parseClock parses a time of day such as 1am, 09:30, 09:30:15, noon
+// or midnight.
+func (p *parser) parseClock() (time.Time, error) {
+	h := p.next()
+	switch h.typ {
+	case tokenMidnight:
+		return time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), nil
+	case tokenNoon:
+		return time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC), nil
+	case tokenDigit:
+	default:
+		return time.Time{}, newParseError(h, "expected time", tokenDigit, tokenMidnight, tokenNoon)
+	}
+	t := p.next()
+	switch t.typ {
+	case tokenTwelveHour:
+		rv, err := time.Parse("3pm", h.val+t.val)
+		if err != nil {
+			return time.Time{}, spanError(h, t, "invalid time")
+		}
+		return rv, nil
+	case tokenColon:
+		m := p.next()
+		if p.peek().typ != tokenColon {
+			rv, err := time.Parse("15:04", h.val+":"+m.val)
+			if err != nil {
+				return time.Time{}, spanError(h, m, "invalid time")
+			}
+			return rv, nil
+		}
+		p.next()
+		s := p.next()
+		rv, err := time.Parse("15:04:05", h.val+":"+m.val+":"+s.val)
+		if err != nil {
+			return time.Time{}, spanError(h, s, "invalid time")
+		}
+		return rv, nil
+	}
+	return time.Time{}, newParseError(t, "expected time", tokenColon, tokenTwelveHour)
 }
 
 func (p *parser) parseTwentyFourHour(h, m token) error {
@@ -321,9 +677,9 @@ func (p *parser) parseTwentyFourHour(h, m token) error {
 		p.next()
 		return p.parseTwentyFourHourWithSeconds(h, m)
 	}
-	t, err := time.ParseInLocation("15:04", h.val+":"+m.val, p.loc)
+	t, err := time.Parse("15:04", h.val+":"+m.val)
 	if err != nil {
-		return err
+		return spanError(h, m, "invalid time")
 	}
 	hour, min, _ := t.Clock()
 	expr := Hour(hour)
@@ -335,17 +691,27 @@ func (p *parser) parseTwentyFourHour(h, m token) error {
 
 func (p *parser) parseTwentyFourHourWithSeconds(h, m token) error {
 	s := p.next()
-	t, err := time.ParseInLocation("15:04:05", h.val+":"+m.val+":"+s.val, p.loc)
+	t, err := time.Parse("15:04:05", h.val+":"+m.val+":"+s.val)
 	if err != nil {
-		return err
+		return spanError(h, s, "invalid time")
 	}
 	hour, min, sec := t.Clock()
 	exprs := []Expression{Hour(hour)}
-	if min != 0 || sec != 0 {
-		exprs = append(exprs, Minute(min))
-	}
-	if sec != 0 {
-		exprs = append(exprs, Second(sec))
+	if p.peek().typ == tokenDot {
+		p.next()
+		fraction, err := p.fraction()
+		if err != nil {
+			return err
+		}
+		exprs = append(exprs, Minute(min), Second(sec))
+		exprs = append(exprs, fraction...)
+	} else {
+		if min != 0 || sec != 0 {
+			exprs = append(exprs, Minute(min))
+		}
+		if sec != 0 {
+			exprs = append(exprs, Second(sec))
+		}
 	}
 	expr := exprs[0]
 	if len(exprs) > 1 {
@@ -354,40 +720,179 @@ func (p *parser) parseTwentyFourHourWithSeconds(h, m token) error {
 	return p.add(expr)
 }
 
-func (p *parser) parseTwelveHour(h, ampm token) error {
-	t, err := time.ParseInLocation("3pm", h.val+ampm.val, p.loc)
+// parseFraction parses a fraction of each second such as ".500" or
+// ".000250 of each second", following the dot.
+func (p *parser) parseFraction() error {
+	exprs, err := p.fraction()
 	if err != nil {
 		return err
 	}
+	if p.peek().typ == tokenOf {
+		p.next()
+		t := p.next()
+		if t.typ != tokenEvery {
+			return newParseError(t, "expected each", tokenEvery)
+		}
+		t = p.next()
+		if t.typ != tokenUnitSecond {
+			return newParseError(t, "expected second", tokenUnitSecond)
+		}
+	}
+	if len(exprs) == 1 {
+		return p.add(exprs[0])
+	}
+	return p.add(Intersect(exprs...))
+}
+
+// fraction parses the digits of a fraction of a second following a dot.
+// The millisecond is returned, followed by the microsecond within it if
+// it is not zero, as in ".000250".
+func (p *parser) fraction() ([]Expression, error) {
+	d := p.next()
+	if d.typ != tokenDigit || len(d.val) > 6 {
+		return nil, newParseError(d, "expected one to six digits", tokenDigit)
+	}
+	n, err := strconv.Atoi(d.val)
+	if err != nil {
+		return nil, newParseError(d, "invalid number")
+	}
+	for i := len(d.val); i < 6; i++ {
+		n *= 10
+	}
+	exprs := []Expression{Millisecond(n / 1000)}
+	if n%1000 != 0 {
+		exprs = append(exprs, Microsecond(n%1000))
+	}
+	return exprs, nil
+}
+
+// parseStarting parses the optional offset of a step such as
+// "starting at 1am" for hours or "starting at :05" for minutes
+// and seconds. The offset is zero if there is no starting clause.
+func (p *parser) parseStarting(unit tokenType) (int, error) {
+	if p.peek().typ != tokenStarting {
+		return 0, nil
+	}
+	p.next()
+	t := p.next()
+	if t.typ != tokenAt {
+		return 0, newParseError(t, "expected at", tokenAt)
+	}
+	t = p.next()
+	if unit != tokenUnitHour {
+		if t.typ != tokenColon {
+			return 0, newParseError(t, "expected colon", tokenColon)
+		}
+		d := p.next()
+		if d.typ != tokenDigit || len(d.val) != 2 {
+			return 0, newParseError(d, "expected two digits", tokenDigit)
+		}
+		n, err := strconv.Atoi(d.val)
+		if err != nil {
+			return 0, newParseError(d, "invalid number")
+		}
+		return n, nil
+	}
+	if t.typ != tokenDigit {
+		return 0, newParseError(t, "expected hour", tokenDigit)
+	}
+	u := p.next()
+	switch u.typ {
+	case tokenTwelveHour:
+		h, err := time.Parse("3pm", t.val+u.val)
+		if err != nil {
+			return 0, spanError(t, u, "invalid time")
+		}
+		return h.Hour(), nil
+	case tokenColon:
+		m := p.next()
+		h, err := time.Parse("15:04", t.val+":"+m.val)
+		if err != nil {
+			return 0, spanError(t, m, "invalid time")
+		}
+		if h.Minute() != 0 {
+			return 0, newParseError(m, "expected top of the hour")
+		}
+		return h.Hour(), nil
+	}
+	return 0, newParseError(u, "expected hour", tokenTwelveHour, tokenColon)
+}
+
+func (p *parser) parseTwelveHour(h, ampm token) error {
+	t, err := time.Parse("3pm", h.val+ampm.val)
+	if err != nil {
+		return spanError(h, ampm, "invalid time")
+	}
 	hour := t.Hour()
 	expr := Hour(hour)
 	return p.add(expr)
 }
 
-func (p *parser) parseUnitHour(d token) error {
-	hour, err := strconv.Atoi(d.val)
+// epoch is the time from which continuous intervals are counted.
+var epoch = time.Unix(0, 0).UTC()
+
+// parseStep parses a step of d hours, minutes or seconds given by the
+// unit u. A step restarts at the top of each day, hour or minute unless
+// it is followed by "continuously", in which case it is an interval of
+// elapsed time counted from the epoch.
+func (p *parser) parseStep(d, u token) error {
+	n, err := strconv.Atoi(d.val)
+	if err != nil {
+		return newParseError(d, "invalid number")
+	}
+	continuous := p.peek().typ == tokenContinuously
+	if continuous {
+		p.next()
+	}
+	offset, err := p.parseStarting(u.typ)
 	if err != nil {
 		return err
 	}
-	expr := Hourly(hour)
+	var step func(n, offset int) Expression
+	var max int
+	var unit time.Duration
+	var within string
+	switch u.typ {
+	case tokenUnitHour:
+		step, max, unit, within = HourStep, 23, time.Hour, "a day"
+	case tokenUnitMinute:
+		step, max, unit, within = MinuteStep, 59, time.Minute, "an hour"
+	default:
+		step, max, unit, within = SecondStep, 59, time.Second, "a minute"
+	}
+	if continuous {
+		err = p.validate(step(1, offset))
+		if err != nil {
+			return err
+		}
+		expr := Every(time.Duration(n)*unit, epoch.Add(time.Duration(offset)*unit))
+		return p.add(expr)
+	}
+	expr := step(n, offset)
+	if n > max {
+		err = Validate(expr)
+		e := spanError(p.token(p.start), p.token(p.pos-1), d.val+" "+u.val+" is "+within+" or longer, add \"continuously\" to count intervals from the Unix epoch")
+		e.Err = err
+		return e
+	}
 	return p.add(expr)
 }
 
-func (p *parser) parseUnitMinute(d token) error {
-	min, err := strconv.Atoi(d.val)
+func (p *parser) parseUnitMicrosecond(d token) error {
+	us, err := strconv.Atoi(d.val)
 	if err != nil {
-		return err
+		return newParseError(d, "invalid number")
 	}
-	expr := Minutely(min)
+	expr := Microsecondly(us)
 	return p.add(expr)
 }
 
-func (p *parser) parseUnitSecond(d token) error {
-	sec, err := strconv.Atoi(d.val)
+func (p *parser) parseUnitMillisecond(d token) error {
+	ms, err := strconv.Atoi(d.val)
 	if err != nil {
-		return err
+		return newParseError(d, "invalid number")
 	}
-	expr := Secondly(sec)
+	expr := Millisecondly(ms)
 	return p.add(expr)
 }
 
@@ -416,38 +921,85 @@ func (p *parser) parseWeekday(t token) error {
 }
 
 func (p *parser) parseWeekly() error {
+	if p.end() {
+		return p.add(Weekday(time.Sunday))
+	}
 	t := p.next()
 	switch t.typ {
-	case tokenEOF:
-		p.exprs = append(p.exprs, Weekday(time.Sunday))
-		return nil
 	case tokenOn:
 		return p.parseOn()
 	}
-	return newParseError(t, "unexpected token")
+	return newParseError(t, "unexpected token", tokenOn)
+}
+
+func (p *parser) parseYear(t token) error {
+	year, err := strconv.Atoi(t.val)
+	if err != nil {
+		return newParseError(t, "invalid number")
+	}
+	expr := Year(year)
+	return p.add(expr)
+}
+
+// isYear reports whether t is a four digit year. Numbers with a leading
+// zero, such as 0930, are not years.
+func isYear(t token) bool {
+	return t.typ == tokenDigit && len(t.val) == 4 && t.val[0] != '0'
 }
 
 func (p *parser) parseYearly() error {
 	expr := Intersect(Month(time.January), Day(1))
-	err := p.add(expr)
-	if err != nil {
-		return err
+	return p.add(expr)
+}
+
+// end reports whether the next token ends the current clause.
+func (p *parser) end() bool {
+	switch p.peek().typ {
+	case tokenEOF, tokenExcept, tokenOr, tokenRightParen, tokenZone:
+		return true
 	}
-	t := p.next()
-	switch t.typ {
-	case tokenEOF:
-		return nil
-	case tokenAt:
-		return p.parseAt()
+	return false
+}
+
+// expr returns the union of the parsed terms.
+func (p *parser) expr() Expression {
+	expr := intersect(p.exprs)
+	if len(p.terms) == 0 {
+		return expr
+	}
+	return Union(append(p.terms, expr)...)
+}
+
+func (p *parser) backup() {
+	p.pos--
+}
+
+func (p *parser) parseZone(t token) error {
+	switch {
+	case p.join:
+		return newParseError(t, "incomplete expression")
+	case len(p.exprs) == 0:
+		return newParseError(t, "expected expression before time zone")
+	}
+	loc, err := loadLocation(t.val, p.abbrs)
+	if err != nil {
+		return newParseError(t, "unknown time zone")
 	}
-	return newParseError(t, "unexpected token")
+	expr := In(intersect(p.exprs), loc)
+	p.exprs = []Expression{expr}
+	return p.parseExpr()
 }
 
 func (p *parser) peek() token {
-	if p.pos >= len(p.tokens) {
-		return token{tokenEOF, ""}
+	return p.token(p.pos)
+}
+
+// token returns the token at position i, or EOF if i is past the end.
+func (p *parser) token(i int) token {
+	if i >= len(p.tokens) {
+		return token{tokenEOF, "", len(p.input)}
 	}
-	return p.tokens[p.pos]
+	return p.tokens[i]
 }
 
 func (p *parser) next() token {
@@ -455,3 +1007,12 @@ func (p *parser) next() token {
 	p.pos++
 	return t
 }
+
+// intersect returns the intersection of exprs, or the expression
+// itself if there is only one.
+func intersect(exprs []Expression) Expression {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return Intersect(exprs...)
+}
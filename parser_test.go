@@ -25,6 +25,12 @@ func TestParse(t *testing.T) {
 		{"at noon", Hour(12)},
 		{"at 3pm", Hour(15)},
 
+		{"between 1am and 5am", TimeRange(1, 0, 0, 5, 0, 0)},
+		{"from 09:00 to 17:30", TimeRange(9, 0, 0, 17, 30, 0)},
+		{"from noon to 17:30:15", TimeRange(12, 0, 0, 17, 30, 15)},
+		{"between 10pm and 2am", TimeRange(22, 0, 0, 2, 0, 0)},
+		{"Sat/Sun between 1am and 5am", Intersect(Union(Weekday(time.Saturday), Weekday(time.Sunday)), TimeRange(1, 0, 0, 5, 0, 0))},
+
 		{"daily at midnight", Hour(0)},
 		{"daily at noon", Hour(12)},
 		{"daily at 3pm", Hour(15)},
@@ -63,6 +69,30 @@ func TestParse(t *testing.T) {
 		{"every 2 hours", Hourly(2)},
 		{"every 15 minutes", Minutely(15)},
 		{"every 30 seconds", Secondly(30)},
+		{"every 7 minutes", Minutely(7)},
+		{"every 15 minutes starting at :05", MinuteStep(15, 5)},
+		{"every 20 seconds starting at :10", SecondStep(20, 10)},
+		{"every 3 hours starting at 1am", HourStep(3, 1)},
+		{"every 6 hours starting at 13:00", HourStep(6, 13)},
+		{"every 15 minutes starting at :05 on Monday", Intersect(MinuteStep(15, 5), Weekday(time.Monday))},
+		{"every 90 minutes continuously", Every(90*time.Minute, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC))},
+		{"every 7 minutes continuously starting at :05", Every(7*time.Minute, time.Date(1970, 1, 1, 0, 5, 0, 0, time.UTC))},
+		{"every 25 hours continuously starting at 1am", Every(25*time.Hour, time.Date(1970, 1, 1, 1, 0, 0, 0, time.UTC))},
+		{"every 90 seconds continuously", Every(90*time.Second, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC))},
+		{"every 250 milliseconds", Millisecondly(250)},
+		{"every 250ms", Millisecondly(250)},
+		{"every millisecond", Millisecondly(1)},
+		{".500", Millisecond(500)},
+		{".5", Millisecond(500)},
+		{"at .500 of each second", Millisecond(500)},
+		{".250 of every second", Millisecond(250)},
+		{".000250", Intersect(Millisecond(0), Microsecond(250))},
+		{".001500", Intersect(Millisecond(1), Microsecond(500))},
+		{"09:30:15.250", Intersect(Hour(9), Minute(30), Second(15), Millisecond(250))},
+		{"09:30:00.000", Intersect(Hour(9), Minute(30), Second(0), Millisecond(0))},
+		{"mon .500", Intersect(Weekday(time.Monday), Millisecond(500))},
+		{"every 100 microseconds", Microsecondly(100)},
+		{"every 100us", Microsecondly(100)},
 
 		{"3pm and 9pm", Union(Hour(15), Hour(21))},
 		{"15:00 and 21:00", Union(Hour(15), Hour(21))},
@@ -93,6 +123,84 @@ func TestParse(t *testing.T) {
 		{"every April 19th at 3pm", Intersect(Month(time.April), Day(19), Hour(15))},
 		{"every 2 hours on Sunday", Intersect(Hourly(2), Weekday(time.Sunday))},
 		{"Tue/Thu at 4am", Intersect(Union(Weekday(time.Tuesday), Weekday(time.Thursday)), Hour(4))},
+
+		{"daily at 9am except in December", Intersect(Hour(9), Except(Month(time.December)))},
+		{"daily at 9am excluding December", Intersect(Hour(9), Except(Month(time.December)))},
+		{"daily at 9am but not in December", Intersect(Hour(9), Except(Month(time.December)))},
+		{"daily at 9am not in December", Intersect(Hour(9), Except(Month(time.December)))},
+		{"every day except Sat/Sun", Intersect(Daily(), Except(Union(Weekday(time.Saturday), Weekday(time.Sunday))))},
+		{"at noon except Sunday in June", Intersect(Hour(12), Except(Intersect(Weekday(time.Sunday), Month(time.June))))},
+		{"at noon except Sunday except June", Intersect(Hour(12), Except(Weekday(time.Sunday)), Except(Month(time.June)))},
+		{"monthly except in January", Intersect(Day(1), Except(Month(time.January)))},
+
+		{"(Mon at 9am) or (Fri at 5pm)", Union(Intersect(Weekday(time.Monday), Hour(9)), Intersect(Weekday(time.Friday), Hour(17)))},
+		{"Mon at 9am or Fri at 5pm", Union(Intersect(Weekday(time.Monday), Hour(9)), Intersect(Weekday(time.Friday), Hour(17)))},
+		{"Mon at 9am; Fri at 5pm", Union(Intersect(Weekday(time.Monday), Hour(9)), Intersect(Weekday(time.Friday), Hour(17)))},
+		{"(Mon at 9am) and (Fri at 5pm)", Union(Intersect(Weekday(time.Monday), Hour(9)), Intersect(Weekday(time.Friday), Hour(17)))},
+		{"Mon/Wed at 9am or noon", Union(Intersect(Union(Weekday(time.Monday), Weekday(time.Wednesday)), Hour(9)), Hour(12))},
+		{"at noon (Mon or Fri)", Intersect(Hour(12), Union(Weekday(time.Monday), Weekday(time.Friday)))},
+		{"( at noon )", Hour(12)},
+		{"((noon))", Hour(12)},
+		{"daily except Sunday or noon", Union(Intersect(Daily(), Except(Weekday(time.Sunday))), Hour(12))},
+		{"(daily except Sunday) at 9am", Intersect(Intersect(Daily(), Except(Weekday(time.Sunday))), Hour(9))},
+		{"daily at 9am except (Sat or Sun)", Intersect(Hour(9), Except(Union(Weekday(time.Saturday), Weekday(time.Sunday))))},
+		{"2026", Year(2026)},
+		{"in 2026", Year(2026)},
+		{"2026-12-25", Intersect(Year(2026), Month(time.December), Day(25))},
+		{"2026-12-25 at 9am", Intersect(Year(2026), Month(time.December), Day(25), Hour(9))},
+		{"2026-12-25 at 17:30", Intersect(Year(2026), Month(time.December), Day(25), Intersect(Hour(17), Minute(30)))},
+		{"Dec 25", Intersect(Month(time.December), Day(25))},
+		{"Dec 25 2026", Intersect(Year(2026), Month(time.December), Day(25))},
+		{"Dec 25, 2026", Intersect(Year(2026), Month(time.December), Day(25))},
+		{"December 25th, 2026 at 9am", Intersect(Year(2026), Month(time.December), Day(25), Hour(9))},
+		{"Dec 2026", Intersect(Year(2026), Month(time.December))},
+		{"May 3pm", Intersect(Month(time.May), Hour(15))},
+		{"Feb 29", Intersect(Month(time.February), Day(29))},
+		{"Dec 24 and 25th", Intersect(Month(time.December), Union(Day(24), Day(25)))},
+		{"noon and 2026-12-25", Union(Hour(12), Intersect(Year(2026), Month(time.December), Day(25)))},
+		{"daily at 9am except 2026-12-25", Intersect(Hour(9), Except(Intersect(Year(2026), Month(time.December), Day(25))))},
+		{"Mon in 2026", Intersect(Weekday(time.Monday), Year(2026))},
+
+		{"Mon at 9am or Fri at 5pm or daily", Union(Intersect(Weekday(time.Monday), Hour(9)), Intersect(Weekday(time.Friday), Hour(17)), Daily())},
+	}
+	for _, tt := range tests {
+		have, err := Parse(tt.in, time.UTC)
+		if err != nil {
+			t.Fatalf("Parse(%q) %v", tt.in, err)
+		} else if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("Parse(%q)\nhave %#v\nwant %#v", tt.in, have, tt.want)
+		}
+	}
+}
+
+func TestParseZone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ist := time.FixedZone("+05:30", 5*60*60+30*60)
+	pst := time.FixedZone("-08:00", -8*60*60)
+	tests := []struct {
+		in   string
+		want Expression
+	}{
+		{"at 9am America/New_York", In(Hour(9), ny)},
+		{"at 17:00 UTC", In(Hour(17), time.UTC)},
+		{"at 9am EST", In(Hour(9), ny)},
+		{"at 9am est", In(Hour(9), ny)},
+		{"at 9am +05:30", In(Hour(9), ist)},
+		{"at 9am +0530", In(Hour(9), ist)},
+		{"at 9am -8", In(Hour(9), pst)},
+		{"daily UTC", In(Daily(), time.UTC)},
+		{"Mon at 9am America/New_York", In(Intersect(Weekday(time.Monday), Hour(9)), ny)},
+		{"9am and 5pm UTC", In(Union(Hour(9), Hour(17)), time.UTC)},
+		{"at 9am America/New_York or at 9am Asia/Tokyo", Union(In(Hour(9), ny), In(Hour(9), tokyo))},
+		{"(Mon at 9am UTC) at noon", Intersect(In(Intersect(Weekday(time.Monday), Hour(9)), time.UTC), Hour(12))},
+		{"at 9am UTC except Sunday", Intersect(In(Hour(9), time.UTC), Except(Weekday(time.Sunday)))},
 	}
 	for _, tt := range tests {
 		have, err := Parse(tt.in, time.UTC)
@@ -104,6 +212,60 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParserAbbreviations(t *testing.T) {
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abbrs := DefaultAbbreviations()
+	abbrs["NZST"] = "Pacific/Auckland"
+	delete(abbrs, "EST")
+	p := Parser{Abbreviations: abbrs}
+	have, err := p.Parse("at 9am nzst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := In(Hour(9), auckland)
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have %#v\nwant %#v", have, want)
+	}
+	if _, err := p.Parse("at 9am EST"); err == nil {
+		t.Errorf("EST should not be recognized")
+	}
+	if _, err := Parse("at 9am NZST", time.UTC); err == nil {
+		t.Errorf("NZST should not be recognized by default")
+	}
+	if _, err := Parse("at 9am EST", time.UTC); err != nil {
+		t.Errorf("EST should be recognized by default: %v", err)
+	}
+	if _, err := (Parser{}).Parse("at 9am EST"); err != nil {
+		t.Errorf("EST should be recognized by the zero Parser: %v", err)
+	}
+}
+
+func TestParserLocation(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	have, err := Parser{Location: ny}.Parse("daily at 9am")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := In(Hour(9), ny)
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have %#v\nwant %#v", have, want)
+	}
+	have, err = Parse("daily at 9am", ny)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = Hour(9)
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("Parse should ignore its location\nhave %#v\nwant %#v", have, want)
+	}
+}
+
 func TestParseError(t *testing.T) {
 	var tests = []string{
 		"",
@@ -120,6 +282,63 @@ func TestParseError(t *testing.T) {
 		"every",
 		"in noon",
 		"at noon and",
+		"except Sunday",
+		"daily except",
+		"daily except except Sunday",
+		"daily and except Sunday",
+		"daily but Sunday",
+		"daily except in",
+		"(daily",
+		"daily)",
+		"()",
+		"(daily or)",
+		"daily or",
+		"or daily",
+		"daily and or noon",
+		"daily;",
+		"every 15 minutes starting",
+		"every 15 minutes starting at 5",
+		"every 15 minutes starting at :5",
+		"every 15 minutes starting at :60",
+		"every 60 minutes",
+		"between",
+		"between 1am",
+		"between 1am to 5am",
+		"from 9:00 and 17:00",
+		"from 9 to 17",
+		"between 13pm and 5pm",
+		"from 09:00 to 24:00",
+		"every 3 hours starting at :05",
+		"every 0 minutes continuously",
+		"every 90 minutes continuously starting at :60",
+		"every continuously",
+		".1234567",
+		". 5",
+		".500 of each minute",
+		".500 of second",
+		"at .",
+		"daily continuously",
+		"every 3 hours starting at 1:30",
+		"(daily except)",
+		"2026-13-01",
+		"2026-02-30",
+		"2026-12",
+		"2026-12-",
+		"Feb 30",
+		"Feb 29 2026",
+		"Apr 31st",
+		"in 26",
+		"0930",
+		"at 0930",
+		"in 0930",
+		"every 2026",
+		"mon 0930",
+		"Dec 0930",
+		"UTC",
+		"at 9am Mars/Olympus_Mons",
+		"at 9am +25",
+		"at 9am +5:3",
+		"at 9am and UTC",
 	}
 	for _, tt := range tests {
 		have, err := Parse(tt, time.UTC)
@@ -128,3 +347,58 @@ func TestParseError(t *testing.T) {
 		}
 	}
 }
+
+func TestParseErrorPosition(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ParseError
+	}{
+		{
+			"daily at 25:00",
+			ParseError{Offset: 9, Column: 10, Token: "25:00", Message: "invalid time"},
+		},
+		{
+			"Mon at",
+			ParseError{Offset: 6, Column: 7, Message: "expected time or time constant",
+				Expected: []string{"digit", "dot", "midnight", "noon"}},
+		},
+		{
+			"  daily)",
+			ParseError{Offset: 7, Column: 8, Token: ")", Message: "unexpected closing parenthesis"},
+		},
+		{
+			"every 5µs at 25:00",
+			ParseError{Offset: 14, Column: 14, Token: "25:00", Message: "invalid time"},
+		},
+		{
+			"every 3 hours starting at 1:30",
+			ParseError{Offset: 28, Column: 29, Token: "30", Message: "expected top of the hour"},
+		},
+		{
+			"Mon at 9am Nowhere",
+			ParseError{Offset: 11, Column: 12, Token: "Nowhere", Message: "unknown word"},
+		},
+		{
+			"every Wendesday at noon",
+			ParseError{Offset: 6, Column: 7, Token: "Wendesday", Message: "unknown word", Suggestion: "wednesday"},
+		},
+		{
+			"daily at 9am!",
+			ParseError{Offset: 12, Column: 13, Token: "!", Message: "invalid character"},
+		},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.in, time.UTC)
+		have, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("Parse(%q)\nhave %v\nwant *ParseError", tt.in, err)
+			continue
+		}
+		want := tt.want
+		want.Input = tt.in
+		want.end = have.end
+		if !reflect.DeepEqual(*have, want) {
+			t.Errorf("Parse(%q)\nhave %#v\nwant %#v", tt.in, *have, want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package te
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schedule is an expression together with the text it was parsed from.
+// It may be used as a field of configuration structs decoded from text
+// and, through a pointer, as a command line flag. The zero value is a
+// schedule that is never active.
+type Schedule struct {
+	Expr Expression
+	Text string
+
+	// Location, if not nil, is the location in which the schedule is
+	// evaluated, as by In. It may be set before the text is unmarshaled.
+	Location *time.Location
+}
+
+// ParseSchedule parses s into a schedule evaluated in loc. If loc is
+// nil, the schedule is evaluated in the location of the given time.
+func ParseSchedule(s string, loc *time.Location) (Schedule, error) {
+	sched := Schedule{Location: loc}
+	err := sched.UnmarshalText([]byte(s))
+	if err != nil {
+		return Schedule{}, err
+	}
+	return sched, nil
+}
+
+// IsActive reports whether the schedule is active at t.
+func (s Schedule) IsActive(t time.Time) bool {
+	if s.Expr == nil {
+		return false
+	}
+	return s.expr().IsActive(t)
+}
+
+// Next returns the next active time of the schedule after t.
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.Expr == nil {
+		return time.Time{}
+	}
+	return s.expr().Next(t)
+}
+
+func (s Schedule) expr() Expression {
+	if s.Location == nil {
+		return s.Expr
+	}
+	return In(s.Expr, s.Location)
+}
+
+// String returns the text of the schedule.
+func (s Schedule) String() string {
+	return s.Text
+}
+
+// MarshalText returns the text of the schedule.
+func (s Schedule) MarshalText() ([]byte, error) {
+	return []byte(s.Text), nil
+}
+
+// UnmarshalText parses text into the schedule. Empty text resets the
+// schedule to one that is never active, keeping its location.
+func (s *Schedule) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		s.Expr, s.Text = nil, ""
+		return nil
+	}
+	expr, err := Parse(string(text), s.loc())
+	if err != nil {
+		return err
+	}
+	s.Expr, s.Text = expr, string(text)
+	return nil
+}
+
+// Set parses value into the schedule. It implements flag.Value.
+func (s *Schedule) Set(value string) error {
+	return s.UnmarshalText([]byte(value))
+}
+
+// Value returns the text of the schedule for storage in a database.
+// A schedule without text is stored in the JSON form of its expression,
+// and the zero value is stored as NULL. It implements driver.Valuer.
+func (s Schedule) Value() (driver.Value, error) {
+	switch {
+	case s.Text != "":
+		return s.Text, nil
+	case s.Expr != nil:
+		data, err := MarshalJSON(s.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	return nil, nil
+}
+
+// Scan parses a schedule read from a database. Values beginning with
+// an opening brace are read in the JSON form written by MarshalJSON and
+// others are parsed as text. NULL resets the schedule to one that is
+// never active. It implements sql.Scanner.
+func (s *Schedule) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("te: cannot scan %T into Schedule", src)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(text), "{") {
+		return s.UnmarshalText([]byte(text))
+	}
+	expr, err := UnmarshalJSON([]byte(text))
+	if err != nil {
+		return err
+	}
+	s.Expr, s.Text = expr, ""
+	return nil
+}
+
+func (s *Schedule) loc() *time.Location {
+	if s.Location == nil {
+		return time.Local
+	}
+	return s.Location
+}
@@ -0,0 +1,272 @@
+package te
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+)
+
+func init() {
+	sql.Register("te", &fakeDriver{})
+}
+
+func TestParseSchedule(t *testing.T) {
+	s, err := ParseSchedule("Mon at 9am", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := time.Date(2016, 1, 4, 9, 0, 0, 0, time.UTC)
+	if next := s.Next(now); !next.Equal(want) {
+		t.Errorf("have next %v\nwant next %v", next, want)
+	}
+	if s.String() != "Mon at 9am" {
+		t.Errorf("have %q\nwant %q", s.String(), "Mon at 9am")
+	}
+	_, err = ParseSchedule("Mon at 25:00", time.UTC)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("have %v\nwant *ParseError", err)
+	}
+}
+
+func TestScheduleLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ParseSchedule("at 9am", tokyo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC)
+	next := s.Next(now)
+	if !next.Equal(want) || next.Location() != time.UTC {
+		t.Errorf("have next %v\nwant next %v", next, want)
+	}
+	if !s.IsActive(want) {
+		t.Errorf("have isActive false\nwant isActive true")
+	}
+}
+
+func TestScheduleZero(t *testing.T) {
+	var s Schedule
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	if s.IsActive(now) {
+		t.Errorf("have isActive true\nwant isActive false")
+	}
+	if next := s.Next(now); !next.IsZero() {
+		t.Errorf("have next %v\nwant zero time", next)
+	}
+}
+
+func TestScheduleText(t *testing.T) {
+	var config struct {
+		Schedule Schedule `json:"schedule"`
+		Optional Schedule `json:"optional"`
+	}
+	config.Schedule.Location = time.UTC
+	err := json.Unmarshal([]byte(`{"schedule":"daily at noon","optional":""}`), &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := fmt.Sprintf("%#v", config.Schedule.Expr); have != "te.Hour(12)" {
+		t.Errorf("have %s\nwant te.Hour(12)", have)
+	}
+	if config.Schedule.Location != time.UTC {
+		t.Errorf("have location %v\nwant location UTC", config.Schedule.Location)
+	}
+	if config.Optional.Expr != nil {
+		t.Errorf("have %#v\nwant nil expression", config.Optional.Expr)
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"schedule":"daily at noon","optional":""}`
+	if string(data) != want {
+		t.Errorf("have %s\nwant %s", data, want)
+	}
+	err = json.Unmarshal([]byte(`{"schedule":"daily at"}`), &config)
+	if err == nil {
+		t.Errorf("want parse error")
+	}
+}
+
+func TestScheduleFlag(t *testing.T) {
+	var s Schedule
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Var(&s, "schedule", "schedule")
+	err := fs.Parse([]string{"-schedule", "every 15 minutes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := fmt.Sprintf("%#v", s.Expr); have != "te.Minutely(15)" {
+		t.Errorf("have %s\nwant te.Minutely(15)", have)
+	}
+	err = fs.Parse([]string{"-schedule", "evry 15 minutes"})
+	if err == nil {
+		t.Errorf("want parse error")
+	}
+}
+
+// fakeDriver stores values in a single in-memory column. Statements
+// beginning with INSERT append their argument and all other statements
+// query every stored value.
+type fakeDriver struct {
+	mu     sync.Mutex
+	values []driver.Value
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{d}, nil
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c.d, query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	if s.query == "INSERT" {
+		return 1
+	}
+	return 0
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.values = append(s.d.values, args[0])
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	values := make([]driver.Value, len(s.d.values))
+	copy(values, s.d.values)
+	s.d.values = nil
+	return &fakeRows{values: values}, nil
+}
+
+type fakeRows struct {
+	values []driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"schedule"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+func TestScheduleSQL(t *testing.T) {
+	db, err := sql.Open("te", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	schedules := []Schedule{
+		{Expr: Hour(9), Text: "at 9am"},
+		{Expr: Intersect(Weekday(time.Tuesday), Hour(4))},
+		{},
+	}
+	for _, s := range schedules {
+		_, err = db.Exec("INSERT", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	want := []struct {
+		text string
+		expr string
+	}{
+		{"at 9am", "te.Hour(9)"},
+		{"", "te.Intersect(te.Weekday(time.Tuesday), te.Hour(4))"},
+		{"", "<nil>"},
+	}
+	i := 0
+	for ; rows.Next(); i++ {
+		var s Schedule
+		err = rows.Scan(&s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have := fmt.Sprintf("%#v", s.Expr)
+		if s.Text != want[i].text || have != want[i].expr {
+			t.Errorf("row %d\nhave %q %s\nwant %q %s", i, s.Text, have, want[i].text, want[i].expr)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(want) {
+		t.Errorf("have %d rows\nwant %d rows", i, len(want))
+	}
+}
+
+func TestScheduleScanError(t *testing.T) {
+	tests := map[string]interface{}{
+		"parse":   "daily at",
+		"json":    []byte(`{"hour":25}`),
+		"type":    42,
+		"unknown": `{"fortnightly":1}`,
+	}
+	for name, src := range tests {
+		var s Schedule
+		err := s.Scan(src)
+		if err == nil {
+			t.Errorf("%s\nhave %#v\nwant error", name, s.Expr)
+		}
+	}
+	var s Schedule
+	var parseErr *ParseError
+	if err := s.Scan("daily at 25:00"); !errors.As(err, &parseErr) {
+		t.Errorf("have %v\nwant *ParseError", err)
+	}
+}
@@ -1,75 +1,198 @@
 // Package te implements temporal expressions.
+//
+// Constructors given invalid arguments return the nil expression, which
+// is never active and has no next active time. Validate reports the
+// *RangeError or *ArgError that describes the invalid argument.
 package te
 
 import "time"
 
 // Hour returns a temporal expression for an hour.
 // If hour is negative or greater than 23, the nil expression is returned.
+// Validate reports the invalid hour as a *RangeError.
 func Hour(hour int) Expression {
 	if hour < 0 || hour > 23 {
-		return nilExpr{}
+		return outOfRange("Hour", "hour", hour, 0, 23)
 	}
-	return hourExpr(hour)
+	return hourExpr{hour: hour}
 }
 
 // Hourly returns a temporal expression for hourly intervals.
-// If n is less than 1 or greater than 12, the nil expression is returned.
+// If n is less than 1 or greater than 23, the nil expression is returned.
+// Validate reports the invalid n as a *RangeError.
 // If n wraps into a new day, the hours begin counting from zero again.
 func Hourly(n int) Expression {
-	if n < 1 || n > 12 {
-		return nilExpr{}
+	if n < 1 || n > 23 {
+		return outOfRange("Hourly", "n", n, 1, 23)
+	}
+	return HourStep(n, 0)
+}
+
+// HourStep returns a temporal expression for every n hours of the day
+// beginning at the offset hour, such as 1am, 4am, 7am and so on for a step
+// of 3 and an offset of 1. The hours begin counting from the offset again
+// each day. If n is less than 1 or greater than 23, or offset is negative
+// or greater than 23, the nil expression is returned.
+// Validate reports the invalid n or offset as a *RangeError.
+func HourStep(n, offset int) Expression {
+	if n < 1 || n > 23 {
+		return outOfRange("HourStep", "n", n, 1, 23)
+	}
+	if offset < 0 || offset > 23 {
+		return outOfRange("HourStep", "offset", offset, 0, 23)
 	}
 	d := time.Duration(n) * time.Hour
-	return hourlyExpr{n, d}
+	return hourlyExpr{n: n, d: d, offset: offset}
 }
 
 // Minute returns a temporal expression for a minute.
 // If min is negative or greater than 59, the nil expression is returned.
+// Validate reports the invalid min as a *RangeError.
 func Minute(min int) Expression {
 	if min < 0 || min > 59 {
-		return nilExpr{}
+		return outOfRange("Minute", "min", min, 0, 59)
 	}
-	return minuteExpr(min)
+	return minuteExpr{min: min}
 }
 
 // Minutely returns a temporal expression for minutely intervals.
-// If n is less than 1 or greater than 30, the nil expression is returned.
+// If n is less than 1 or greater than 59, the nil expression is returned.
+// Validate reports the invalid n as a *RangeError.
 // If n wraps into a new hour, the minutes begin counting from zero again.
 func Minutely(n int) Expression {
-	if n < 1 || n > 30 {
-		return nilExpr{}
+	if n < 1 || n > 59 {
+		return outOfRange("Minutely", "n", n, 1, 59)
+	}
+	return MinuteStep(n, 0)
+}
+
+// MinuteStep returns a temporal expression for every n minutes of the
+// hour beginning at the offset minute. The minutes begin counting from
+// the offset again each hour. If n is less than 1 or greater than 59, or
+// offset is negative or greater than 59, the nil expression is returned.
+// Validate reports the invalid n or offset as a *RangeError.
+func MinuteStep(n, offset int) Expression {
+	if n < 1 || n > 59 {
+		return outOfRange("MinuteStep", "n", n, 1, 59)
+	}
+	if offset < 0 || offset > 59 {
+		return outOfRange("MinuteStep", "offset", offset, 0, 59)
 	}
 	d := time.Duration(n) * time.Minute
-	return minutelyExpr{n, d}
+	return minutelyExpr{n: n, d: d, offset: offset}
 }
 
 // Second returns a temporal expression for a second.
 // If sec is negative or greater than 59, the nil expression is returned.
+// Validate reports the invalid sec as a *RangeError.
 func Second(sec int) Expression {
 	if sec < 0 || sec > 59 {
-		return nilExpr{}
+		return outOfRange("Second", "sec", sec, 0, 59)
 	}
 	return secondExpr(sec)
 }
 
 // Secondly returns a temporal expression for secondly intervals.
-// If n is less than 1 or greater than 30, the nil expression is returned.
+// If n is less than 1 or greater than 59, the nil expression is returned.
+// Validate reports the invalid n as a *RangeError.
 // If n wraps into a new minute, the seconds begin counting from zero again.
 func Secondly(n int) Expression {
-	if n < 1 || n > 30 {
-		return nilExpr{}
+	if n < 1 || n > 59 {
+		return outOfRange("Secondly", "n", n, 1, 59)
+	}
+	return SecondStep(n, 0)
+}
+
+// SecondStep returns a temporal expression for every n seconds of the
+// minute beginning at the offset second. The seconds begin counting from
+// the offset again each minute. If n is less than 1 or greater than 59, or
+// offset is negative or greater than 59, the nil expression is returned.
+// Validate reports the invalid n or offset as a *RangeError.
+func SecondStep(n, offset int) Expression {
+	if n < 1 || n > 59 {
+		return outOfRange("SecondStep", "n", n, 1, 59)
+	}
+	if offset < 0 || offset > 59 {
+		return outOfRange("SecondStep", "offset", offset, 0, 59)
 	}
 	d := time.Duration(n) * time.Second
-	return secondlyExpr{n, d}
+	return secondlyExpr{n: n, d: d, offset: offset}
+}
+
+// Every returns a temporal expression for intervals of d elapsed time
+// counted continuously from anchor, rather than from the start of each
+// hour, minute or second. For example, Every(7*time.Minute, anchor) is
+// active every seven minutes without an uneven gap at the top of the hour.
+// Each occurrence is active for the largest of an hour, minute, second,
+// millisecond, microsecond or nanosecond that evenly divides d. Intervals
+// are elapsed time, so they are unaffected by daylight saving time.
+// If d is not positive, the nil expression is returned.
+// Validate reports the invalid d as an *ArgError.
+func Every(d time.Duration, anchor time.Time) Expression {
+	if d <= 0 {
+		return invalid("Every", "d", "must be positive")
+	}
+	return everyExpr{d: d, anchor: anchor}
+}
+
+// Millisecond returns a temporal expression for a millisecond of the second.
+// If ms is negative or greater than 999, the nil expression is returned.
+// Validate reports the invalid ms as a *RangeError.
+func Millisecond(ms int) Expression {
+	if ms < 0 || ms > 999 {
+		return outOfRange("Millisecond", "ms", ms, 0, 999)
+	}
+	return millisecondExpr(ms)
+}
+
+// Millisecondly returns a temporal expression for millisecond intervals.
+// If n is less than 1 or greater than 500, the nil expression is returned.
+// Validate reports the invalid n as a *RangeError.
+// If n wraps into a new second, the milliseconds begin counting from zero
+// again.
+func Millisecondly(n int) Expression {
+	if n < 1 || n > 500 {
+		return outOfRange("Millisecondly", "n", n, 1, 500)
+	}
+	d := time.Duration(n) * time.Millisecond
+	return millisecondlyExpr{n, d}
+}
+
+// Microsecond returns a temporal expression for a microsecond of the
+// millisecond. Unlike Millisecond, which counts from the start of the
+// second, us counts from the start of the millisecond, so 1.5ms past
+// each second is Intersect(Millisecond(1), Microsecond(500)).
+// If us is negative or greater than 999, the nil expression is returned.
+// Validate reports the invalid us as a *RangeError.
+func Microsecond(us int) Expression {
+	if us < 0 || us > 999 {
+		return outOfRange("Microsecond", "us", us, 0, 999)
+	}
+	return microsecondExpr(us)
+}
+
+// Microsecondly returns a temporal expression for microsecond intervals.
+// If n is less than 1 or greater than 500, the nil expression is returned.
+// Validate reports the invalid n as a *RangeError.
+// If n wraps into a new millisecond, the microseconds begin counting from
+// zero again.
+func Microsecondly(n int) Expression {
+	if n < 1 || n > 500 {
+		return outOfRange("Microsecondly", "n", n, 1, 500)
+	}
+	d := time.Duration(n) * time.Microsecond
+	return microsecondlyExpr{n, d}
 }
 
 // Day returns a temporal expression for a day of the month.
 // Months without the nth day are ignored. If n is -1, the expression
-// represents the last day of the month. If n is greater than 31 or less
-// than -1, the nil expression is returned.
+// represents the last day of the month. If n is 0, less than -1 or
+// greater than 31, the nil expression is returned. Validate reports it
+// as a *RangeError for the range [1, 31], which does not include the
+// -1 that is also accepted.
 func Day(n int) Expression {
 	if n < -1 || n == 0 || n > 31 {
-		return nilExpr{}
+		return outOfRange("Day", "n", n, 1, 31)
 	}
 	return dayExpr(n)
 }
@@ -80,16 +203,28 @@ func Daily() Expression {
 }
 
 // Weekday returns a temporal expression for weekdays.
+// If d is not a valid weekday, the nil expression is returned.
+// Validate reports the invalid d as a *RangeError.
 func Weekday(d time.Weekday) Expression {
+	if d < time.Sunday || d > time.Saturday {
+		return outOfRange("Weekday", "d", int(d), int(time.Sunday), int(time.Saturday))
+	}
 	return weekdayExpr(d)
 }
 
 // Month returns a temporal expression for months of the year.
+// If month is not a valid month, the nil expression is returned.
+// Validate reports the invalid month as a *RangeError.
 func Month(month time.Month) Expression {
+	if month < time.January || month > time.December {
+		return outOfRange("Month", "month", int(month), int(time.January), int(time.December))
+	}
 	return monthExpr(month)
 }
 
 // Year returns a temporal expression for the given year.
+// The next active time is the start of the year if it has not
+// yet begun. Otherwise, the zero time is returned.
 func Year(year int) Expression {
 	return yearExpr(year)
 }
@@ -110,17 +245,108 @@ func Time(hour, min, sec int) Expression {
 }
 
 // DateRange returns a temporal expression for an inclusive date range.
+// If either month or day is invalid, the nil expression is returned.
+// Validate reports the invalid month or day as a *RangeError.
 func DateRange(m1 time.Month, d1 int, m2 time.Month, d2 int) Expression {
+	for _, arg := range []struct {
+		name     string
+		v        int
+		min, max int
+	}{
+		{"m1", int(m1), 1, 12},
+		{"d1", d1, 1, 31},
+		{"m2", int(m2), 1, 12},
+		{"d2", d2, 1, 31},
+	} {
+		if arg.v < arg.min || arg.v > arg.max {
+			return outOfRange("DateRange", arg.name, arg.v, arg.min, arg.max)
+		}
+	}
 	t1 := time.Date(1, m1, d1, 0, 0, 0, 0, time.UTC)
 	t2 := time.Date(1, m2, d2, 0, 0, 0, 0, time.UTC)
 	return dateRangeExpr{t1, t2}
 }
 
 // TimeRange returns a temporal expression for an inclusive time range.
+// If the end is before the start, the range crosses midnight.
+// If any hour, minute or second is out of range, the nil expression is
+// returned.
+// Validate reports the invalid hour, minute or second as a *RangeError.
 func TimeRange(h1, m1, s1, h2, m2, s2 int) Expression {
+	for _, arg := range []struct {
+		name string
+		v    int
+		max  int
+	}{
+		{"h1", h1, 23},
+		{"m1", m1, 59},
+		{"s1", s1, 59},
+		{"h2", h2, 23},
+		{"m2", m2, 59},
+		{"s2", s2, 59},
+	} {
+		if arg.v < 0 || arg.v > arg.max {
+			return outOfRange("TimeRange", arg.name, arg.v, 0, arg.max)
+		}
+	}
 	t1 := time.Date(1, 1, 1, h1, m1, s1, 0, time.UTC)
 	t2 := time.Date(1, 1, 1, h2, m2, s2, 0, time.UTC)
-	return timeRangeExpr{t1, t2}
+	return timeRangeExpr{t1: t1, t2: t2}
+}
+
+// In returns a temporal expression that evaluates expr in loc rather
+// than in the location of the given time. Times are converted to loc
+// before being passed to expr and the next active time is returned in
+// the location of the given time. If loc is nil, the nil expression
+// is returned.
+// Validate reports the invalid loc as an *ArgError.
+func In(expr Expression, loc *time.Location) Expression {
+	if loc == nil {
+		return invalid("In", "loc", "must not be nil")
+	}
+	return locationExpr{expr, loc}
+}
+
+// WithPolicy returns a copy of expr that handles daylight saving time
+// transitions according to p. The policy applies to the hour, minute
+// and time range expressions within expr, including those nested in
+// compositions. Other expressions are unaffected.
+func WithPolicy(expr Expression, p Policy) Expression {
+	switch e := expr.(type) {
+	case hourExpr:
+		e.policy = p
+		return e
+	case hourlyExpr:
+		e.policy = p
+		return e
+	case minuteExpr:
+		e.policy = p
+		return e
+	case minutelyExpr:
+		e.policy = p
+		return e
+	case timeRangeExpr:
+		e.policy = p
+		return e
+	case locationExpr:
+		e.expr = WithPolicy(e.expr, p)
+		return e
+	case unionExpr:
+		return unionExpr(withPolicy(e, p))
+	case intersectExpr:
+		return intersectExpr(withPolicy(e, p))
+	case exceptExpr:
+		return exceptExpr(withPolicy(e, p))
+	}
+	return expr
+}
+
+func withPolicy(exprs []Expression, p Policy) []Expression {
+	rv := make([]Expression, len(exprs))
+	for i, e := range exprs {
+		rv[i] = WithPolicy(e, p)
+	}
+	return rv
 }
 
 // Union returns a temporal expression that represents the union
@@ -128,7 +354,7 @@ func TimeRange(h1, m1, s1, h2, m2, s2 int) Expression {
 // any of the given expressions are active.
 func Union(exprs ...Expression) Expression {
 	if len(exprs) == 0 {
-		return nilExpr{}
+		return invalid("Union", "exprs", "must not be empty")
 	}
 	return unionExpr(exprs)
 }
@@ -138,7 +364,7 @@ func Union(exprs ...Expression) Expression {
 // active when all of the given expressions are active.
 func Intersect(exprs ...Expression) Expression {
 	if len(exprs) == 0 {
-		return nilExpr{}
+		return invalid("Intersect", "exprs", "must not be empty")
 	}
 	return intersectExpr(exprs)
 }
@@ -149,7 +375,7 @@ func Intersect(exprs ...Expression) Expression {
 // is active when none of the given expressions are active.
 func Except(exprs ...Expression) Expression {
 	if len(exprs) == 0 {
-		return nilExpr{}
+		return invalid("Except", "exprs", "must not be empty")
 	}
 	return exceptExpr(exprs)
 }
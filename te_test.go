@@ -102,9 +102,21 @@ func TestHourly(t *testing.T) {
 			next:     time.Time{},
 			isActive: false,
 		},
-		"greater than 12": {
+		"non-divisor": {
 			n:        13,
 			t:        time.Date(2016, 1, 1, 6, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 13, 0, 0, 0, time.UTC),
+			isActive: false,
+		},
+		"non-divisor wrap": {
+			n:        13,
+			t:        time.Date(2016, 1, 1, 13, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"greater than 23": {
+			n:        24,
+			t:        time.Date(2016, 1, 1, 6, 0, 0, 0, time.UTC),
 			next:     time.Time{},
 			isActive: false,
 		},
@@ -218,8 +230,14 @@ func TestMinutely(t *testing.T) {
 			next:     time.Time{},
 			isActive: false,
 		},
-		"greater than 30": {
+		"non-divisor": {
 			n:        31,
+			t:        time.Date(2016, 1, 1, 0, 40, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 1, 0, 0, 0, time.UTC),
+			isActive: false,
+		},
+		"greater than 59": {
+			n:        60,
 			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
 			next:     time.Time{},
 			isActive: false,
@@ -334,8 +352,14 @@ func TestSecondly(t *testing.T) {
 			next:     time.Time{},
 			isActive: false,
 		},
-		"greater than 30": {
+		"non-divisor": {
 			n:        31,
+			t:        time.Date(2016, 1, 1, 0, 0, 40, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 1, 0, 0, time.UTC),
+			isActive: false,
+		},
+		"greater than 59": {
+			n:        60,
 			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
 			next:     time.Time{},
 			isActive: false,
@@ -355,6 +379,492 @@ func TestSecondly(t *testing.T) {
 	}
 }
 
+func TestHourStep(t *testing.T) {
+	tests := map[string]struct {
+		n        int
+		offset   int
+		t        time.Time
+		next     time.Time
+		isActive bool
+	}{
+		"before offset": {
+			n:        3,
+			offset:   1,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 1, 0, 0, 0, time.UTC),
+			isActive: false,
+		},
+		"offset": {
+			n:        3,
+			offset:   1,
+			t:        time.Date(2016, 1, 1, 1, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 4, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"between": {
+			n:        3,
+			offset:   1,
+			t:        time.Date(2016, 1, 1, 5, 30, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 7, 0, 0, 0, time.UTC),
+			isActive: false,
+		},
+		"wrap": {
+			n:        3,
+			offset:   1,
+			t:        time.Date(2016, 1, 1, 22, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 2, 1, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"negative offset": {
+			n:        3,
+			offset:   -1,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
+			isActive: false,
+		},
+		"offset greater than 23": {
+			n:        3,
+			offset:   24,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
+			isActive: false,
+		},
+	}
+	for name, tt := range tests {
+		expr := HourStep(tt.n, tt.offset)
+		isActive := expr.IsActive(tt.t)
+		if tt.isActive != isActive {
+			t.Errorf("%s\nhave isActive %v\nwant isActive %v", name, isActive, tt.isActive)
+			continue
+		}
+		next := expr.Next(tt.t)
+		if !next.Equal(tt.next) {
+			t.Errorf("%s\nhave next %v\nwant next %v", name, next, tt.next)
+		}
+	}
+}
+
+func TestMinuteStep(t *testing.T) {
+	tests := map[string]struct {
+		n        int
+		offset   int
+		t        time.Time
+		next     time.Time
+		isActive bool
+	}{
+		"before offset": {
+			n:        15,
+			offset:   5,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 5, 0, 0, time.UTC),
+			isActive: false,
+		},
+		"offset": {
+			n:        15,
+			offset:   5,
+			t:        time.Date(2016, 1, 1, 0, 5, 30, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 20, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"wrap": {
+			n:        15,
+			offset:   5,
+			t:        time.Date(2016, 1, 1, 23, 50, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 2, 0, 5, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"offset greater than 59": {
+			n:        15,
+			offset:   60,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
+			isActive: false,
+		},
+	}
+	for name, tt := range tests {
+		expr := MinuteStep(tt.n, tt.offset)
+		isActive := expr.IsActive(tt.t)
+		if tt.isActive != isActive {
+			t.Errorf("%s\nhave isActive %v\nwant isActive %v", name, isActive, tt.isActive)
+			continue
+		}
+		next := expr.Next(tt.t)
+		if !next.Equal(tt.next) {
+			t.Errorf("%s\nhave next %v\nwant next %v", name, next, tt.next)
+		}
+	}
+}
+
+func TestSecondStep(t *testing.T) {
+	tests := map[string]struct {
+		n        int
+		offset   int
+		t        time.Time
+		next     time.Time
+		isActive bool
+	}{
+		"before offset": {
+			n:        20,
+			offset:   10,
+			t:        time.Date(2016, 1, 1, 0, 0, 5, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 10, 0, time.UTC),
+			isActive: false,
+		},
+		"offset": {
+			n:        20,
+			offset:   10,
+			t:        time.Date(2016, 1, 1, 0, 0, 30, 500, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 50, 0, time.UTC),
+			isActive: true,
+		},
+		"wrap": {
+			n:        20,
+			offset:   10,
+			t:        time.Date(2016, 1, 1, 0, 0, 55, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 1, 10, 0, time.UTC),
+			isActive: false,
+		},
+		"offset greater than 59": {
+			n:        20,
+			offset:   60,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
+			isActive: false,
+		},
+	}
+	for name, tt := range tests {
+		expr := SecondStep(tt.n, tt.offset)
+		isActive := expr.IsActive(tt.t)
+		if tt.isActive != isActive {
+			t.Errorf("%s\nhave isActive %v\nwant isActive %v", name, isActive, tt.isActive)
+			continue
+		}
+		next := expr.Next(tt.t)
+		if !next.Equal(tt.next) {
+			t.Errorf("%s\nhave next %v\nwant next %v", name, next, tt.next)
+		}
+	}
+}
+
+func TestEvery(t *testing.T) {
+	anchor := time.Date(2016, 1, 1, 0, 5, 0, 0, time.UTC)
+	tests := map[string]struct {
+		d        time.Duration
+		t        time.Time
+		next     time.Time
+		isActive bool
+	}{
+		"anchor": {
+			d:        7 * time.Minute,
+			t:        anchor,
+			next:     time.Date(2016, 1, 1, 0, 12, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"within occurrence": {
+			d:        7 * time.Minute,
+			t:        time.Date(2016, 1, 1, 0, 12, 30, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 19, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"across hour": {
+			d:        7 * time.Minute,
+			t:        time.Date(2016, 1, 1, 0, 59, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 1, 1, 0, 0, time.UTC),
+			isActive: false,
+		},
+		"before anchor": {
+			d:        7 * time.Minute,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 5, 0, 0, time.UTC),
+			isActive: false,
+		},
+		"before anchor occurrence": {
+			d:        7 * time.Minute,
+			t:        time.Date(2015, 12, 31, 23, 58, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 5, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"greater than hour": {
+			d:        90 * time.Minute,
+			t:        time.Date(2016, 1, 1, 1, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 1, 35, 0, 0, time.UTC),
+			isActive: false,
+		},
+		"sub second": {
+			d:        1500 * time.Millisecond,
+			t:        time.Date(2016, 1, 1, 0, 5, 1, 500000000, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 5, 3, 0, time.UTC),
+			isActive: true,
+		},
+		"zero": {
+			d:        0,
+			t:        anchor,
+			next:     time.Time{},
+			isActive: false,
+		},
+	}
+	for name, tt := range tests {
+		expr := Every(tt.d, anchor)
+		isActive := expr.IsActive(tt.t)
+		if tt.isActive != isActive {
+			t.Errorf("%s\nhave isActive %v\nwant isActive %v", name, isActive, tt.isActive)
+			continue
+		}
+		next := expr.Next(tt.t)
+		if !next.Equal(tt.next) {
+			t.Errorf("%s\nhave next %v\nwant next %v", name, next, tt.next)
+		}
+	}
+}
+
+func TestEveryDistantAnchor(t *testing.T) {
+	now := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		anchor time.Time
+		t      time.Time
+		next   time.Time
+	}{
+		"zero": {
+			anchor: time.Time{},
+			t:      now,
+			next:   time.Date(2026, 3, 3, 0, 2, 0, 0, time.UTC),
+		},
+		"zero within occurrence": {
+			anchor: time.Time{},
+			t:      time.Date(2026, 3, 3, 0, 2, 30, 0, time.UTC),
+			next:   time.Date(2026, 3, 3, 0, 9, 0, 0, time.UTC),
+		},
+		"future": {
+			anchor: time.Date(2500, 1, 1, 0, 0, 0, 0, time.UTC),
+			t:      now,
+			next:   time.Date(2026, 3, 3, 0, 1, 0, 0, time.UTC),
+		},
+		"future nanoseconds": {
+			anchor: time.Date(2500, 1, 1, 0, 0, 0, 1, time.UTC),
+			t:      now,
+			next:   time.Date(2026, 3, 3, 0, 1, 0, 1, time.UTC),
+		},
+	}
+	for name, tt := range tests {
+		expr := Every(7*time.Minute, tt.anchor)
+		next := expr.Next(tt.t)
+		if !next.Equal(tt.next) {
+			t.Errorf("%s\nhave next %v\nwant next %v", name, next, tt.next)
+		}
+		if !expr.IsActive(tt.next) {
+			t.Errorf("%s\nshould be active at %v", name, tt.next)
+		}
+	}
+}
+
+func TestMillisecond(t *testing.T) {
+	ms := int(time.Millisecond)
+	tests := map[string]struct {
+		ms       int
+		t        time.Time
+		next     time.Time
+		isActive bool
+	}{
+		"equal": {
+			ms:       500,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 500*ms, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 1, 500*ms, time.UTC),
+			isActive: true,
+		},
+		"within": {
+			ms:       500,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 500*ms+1, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 1, 500*ms, time.UTC),
+			isActive: true,
+		},
+		"before": {
+			ms:       500,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 250*ms, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 0, 500*ms, time.UTC),
+			isActive: false,
+		},
+		"after": {
+			ms:       500,
+			t:        time.Date(2016, 1, 1, 23, 59, 59, 750*ms, time.UTC),
+			next:     time.Date(2016, 1, 2, 0, 0, 0, 500*ms, time.UTC),
+			isActive: false,
+		},
+		"ms negative": {
+			ms:       -1,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
+			isActive: false,
+		},
+		"ms greater than 999": {
+			ms:       1000,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
+			isActive: false,
+		},
+	}
+	for name, tt := range tests {
+		expr := Millisecond(tt.ms)
+		isActive := expr.IsActive(tt.t)
+		if tt.isActive != isActive {
+			t.Errorf("%s\nhave isActive %v\nwant isActive %v", name, isActive, tt.isActive)
+			continue
+		}
+		next := expr.Next(tt.t)
+		if !next.Equal(tt.next) {
+			t.Errorf("%s\nhave next %v\nwant next %v", name, next, tt.next)
+		}
+	}
+}
+
+func TestMillisecondly(t *testing.T) {
+	ms := int(time.Millisecond)
+	tests := map[string]struct {
+		n        int
+		t        time.Time
+		next     time.Time
+		isActive bool
+	}{
+		"zero": {
+			n:        250,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 0, 250*ms, time.UTC),
+			isActive: true,
+		},
+		"before": {
+			n:        250,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 249*ms, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 0, 250*ms, time.UTC),
+			isActive: false,
+		},
+		"carry": {
+			n:        250,
+			t:        time.Date(2016, 1, 1, 0, 0, 59, 750*ms, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 1, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"wrap": {
+			n:        300,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 950*ms, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 1, 0, time.UTC),
+			isActive: false,
+		},
+		"less than 1": {
+			n:        0,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
+			isActive: false,
+		},
+		"greater than 500": {
+			n:        501,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
+			isActive: false,
+		},
+	}
+	for name, tt := range tests {
+		expr := Millisecondly(tt.n)
+		isActive := expr.IsActive(tt.t)
+		if tt.isActive != isActive {
+			t.Errorf("%s\nhave isActive %v\nwant isActive %v", name, isActive, tt.isActive)
+			continue
+		}
+		next := expr.Next(tt.t)
+		if !next.Equal(tt.next) {
+			t.Errorf("%s\nhave next %v\nwant next %v", name, next, tt.next)
+		}
+	}
+}
+
+func TestMicrosecond(t *testing.T) {
+	us := int(time.Microsecond)
+	ms := int(time.Millisecond)
+	tests := map[string]struct {
+		us       int
+		t        time.Time
+		next     time.Time
+		isActive bool
+	}{
+		"equal": {
+			us:       250,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 250*us, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 0, ms+250*us, time.UTC),
+			isActive: true,
+		},
+		"before": {
+			us:       250,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 5*ms, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 0, 5*ms+250*us, time.UTC),
+			isActive: false,
+		},
+		"carry": {
+			us:       250,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 999*ms+500*us, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 1, 250*us, time.UTC),
+			isActive: false,
+		},
+		"us greater than 999": {
+			us:       1000,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
+			isActive: false,
+		},
+	}
+	for name, tt := range tests {
+		expr := Microsecond(tt.us)
+		isActive := expr.IsActive(tt.t)
+		if tt.isActive != isActive {
+			t.Errorf("%s\nhave isActive %v\nwant isActive %v", name, isActive, tt.isActive)
+			continue
+		}
+		next := expr.Next(tt.t)
+		if !next.Equal(tt.next) {
+			t.Errorf("%s\nhave next %v\nwant next %v", name, next, tt.next)
+		}
+	}
+}
+
+func TestMicrosecondly(t *testing.T) {
+	us := int(time.Microsecond)
+	ms := int(time.Millisecond)
+	tests := map[string]struct {
+		n        int
+		t        time.Time
+		next     time.Time
+		isActive bool
+	}{
+		"zero": {
+			n:        100,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 0, 100*us, time.UTC),
+			isActive: true,
+		},
+		"carry": {
+			n:        100,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 999*ms+900*us, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 1, 0, time.UTC),
+			isActive: true,
+		},
+		"greater than 500": {
+			n:        501,
+			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
+			isActive: false,
+		},
+	}
+	for name, tt := range tests {
+		expr := Microsecondly(tt.n)
+		isActive := expr.IsActive(tt.t)
+		if tt.isActive != isActive {
+			t.Errorf("%s\nhave isActive %v\nwant isActive %v", name, isActive, tt.isActive)
+			continue
+		}
+		next := expr.Next(tt.t)
+		if !next.Equal(tt.next) {
+			t.Errorf("%s\nhave next %v\nwant next %v", name, next, tt.next)
+		}
+	}
+}
+
 func TestDay(t *testing.T) {
 	tests := map[string]struct {
 		day      int
@@ -502,18 +1012,22 @@ func TestMonth(t *testing.T) {
 func TestYear(t *testing.T) {
 	tests := map[string]struct {
 		t        time.Time
+		next     time.Time
 		isActive bool
 	}{
 		"equal": {
 			t:        time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
 			isActive: true,
 		},
 		"before": {
 			t:        time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
 			isActive: false,
 		},
 		"after": {
 			t:        time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Time{},
 			isActive: false,
 		},
 	}
@@ -525,8 +1039,8 @@ func TestYear(t *testing.T) {
 			continue
 		}
 		next := expr.Next(tt.t)
-		if !next.IsZero() {
-			t.Errorf("%s\nhave next %v\nwant next %v", name, next, time.Time{})
+		if !next.Equal(tt.next) {
+			t.Errorf("%s\nhave next %v\nwant next %v", name, next, tt.next)
 		}
 	}
 }
@@ -657,6 +1171,122 @@ func TestTimeRange(t *testing.T) {
 	}
 }
 
+func TestTimeRangeMidnight(t *testing.T) {
+	ms := int(time.Millisecond)
+	tests := map[string]struct {
+		t        time.Time
+		next     time.Time
+		isActive bool
+	}{
+		"start": {
+			t:        time.Date(2016, 1, 1, 22, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 2, 22, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"before midnight": {
+			t:        time.Date(2016, 1, 1, 23, 59, 59, 500*ms, time.UTC),
+			next:     time.Date(2016, 1, 2, 22, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"midnight": {
+			t:        time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 2, 22, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"after midnight": {
+			t:        time.Date(2016, 1, 2, 0, 0, 0, 500*ms, time.UTC),
+			next:     time.Date(2016, 1, 2, 22, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"end": {
+			t:        time.Date(2016, 1, 2, 2, 0, 0, 0, time.UTC),
+			next:     time.Date(2016, 1, 2, 22, 0, 0, 0, time.UTC),
+			isActive: true,
+		},
+		"after end": {
+			t:        time.Date(2016, 1, 2, 2, 0, 0, 500*ms, time.UTC),
+			next:     time.Date(2016, 1, 2, 22, 0, 0, 0, time.UTC),
+			isActive: false,
+		},
+		"before start": {
+			t:        time.Date(2016, 1, 2, 21, 59, 59, 500*ms, time.UTC),
+			next:     time.Date(2016, 1, 2, 22, 0, 0, 0, time.UTC),
+			isActive: false,
+		},
+	}
+	for name, tt := range tests {
+		expr := TimeRange(22, 0, 0, 2, 0, 0)
+		isActive := expr.IsActive(tt.t)
+		if tt.isActive != isActive {
+			t.Errorf("%s\nhave isActive %v\nwant isActive %v", name, isActive, tt.isActive)
+			continue
+		}
+		next := expr.Next(tt.t)
+		if !next.Equal(tt.next) {
+			t.Errorf("%s\nhave next %v\nwant next %v", name, next, tt.next)
+		}
+	}
+}
+
+func TestIn(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expr := In(Hour(9), ny)
+	now := time.Date(2016, 7, 1, 12, 0, 0, 0, time.UTC)
+	if expr.IsActive(now) {
+		t.Errorf("should not be active\ntime %v", now)
+	}
+	now = time.Date(2016, 7, 1, 13, 0, 0, 0, time.UTC)
+	if !expr.IsActive(now) {
+		t.Errorf("should be active\ntime %v", now)
+	}
+	have := next(expr, now, 2)
+	want := []time.Time{
+		time.Date(2016, 7, 2, 13, 0, 0, 0, time.UTC),
+		time.Date(2016, 7, 3, 13, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have next %v\nwant next %v", have, want)
+	}
+	now = time.Date(2016, 12, 1, 0, 0, 0, 0, time.UTC)
+	have = next(expr, now, 1)
+	want = []time.Time{time.Date(2016, 12, 1, 14, 0, 0, 0, time.UTC)}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have next %v\nwant next %v", have, want)
+	}
+	expr = In(Year(2020), ny)
+	have = next(expr, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), 1)
+	want = []time.Time{{}}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have next %v\nwant next %v", have, want)
+	}
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expr = Union(In(Hour(9), london), In(Hour(9), tokyo))
+	now = time.Date(2016, 7, 1, 0, 0, 0, 0, ny)
+	have = next(expr, now, 3)
+	want = []time.Time{
+		time.Date(2016, 7, 1, 4, 0, 0, 0, ny),
+		time.Date(2016, 7, 1, 20, 0, 0, 0, ny),
+		time.Date(2016, 7, 2, 4, 0, 0, 0, ny),
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have next %v\nwant next %v", have, want)
+	}
+	expr = In(Hour(9), nil)
+	if !expr.Next(now).IsZero() {
+		t.Errorf("nil location should be the nil expression")
+	}
+}
+
 func TestUnion(t *testing.T) {
 	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
 	expr := Union(Month(time.January), Day(4))
@@ -742,6 +1372,67 @@ func TestIntersect(t *testing.T) {
 				time.Time{},
 			},
 		},
+		"December 25th, 2026 at 9am": {
+			t:    time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			expr: Intersect(Year(2026), Month(time.December), Day(25), Hour(9)),
+			next: []time.Time{
+				time.Date(2026, 12, 25, 9, 0, 0, 0, time.UTC),
+				time.Time{},
+			},
+		},
+		"every 5th second at 500 milliseconds": {
+			t:    time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			expr: Intersect(Second(5), Millisecond(500)),
+			next: []time.Time{
+				time.Date(2016, 1, 1, 0, 0, 5, 500*int(time.Millisecond), time.UTC),
+				time.Date(2016, 1, 1, 0, 1, 5, 500*int(time.Millisecond), time.UTC),
+			},
+		},
+		"every 250 milliseconds of even seconds": {
+			t:    time.Date(2016, 1, 1, 0, 0, 0, 600*int(time.Millisecond), time.UTC),
+			expr: Intersect(Secondly(2), Millisecondly(250)),
+			next: []time.Time{
+				time.Date(2016, 1, 1, 0, 0, 0, 750*int(time.Millisecond), time.UTC),
+				time.Date(2016, 1, 1, 0, 0, 2, 0, time.UTC),
+				time.Date(2016, 1, 1, 0, 0, 2, 250*int(time.Millisecond), time.UTC),
+			},
+		},
+		"Monday and Tuesday": {
+			t:    time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			expr: Intersect(Weekday(time.Monday), Weekday(time.Tuesday)),
+			next: []time.Time{
+				time.Time{},
+			},
+		},
+		"every minute except every minute": {
+			t:    time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+			expr: Intersect(Minutely(1), Except(Minutely(1))),
+			next: []time.Time{
+				time.Time{},
+			},
+		},
+		"every second except every second": {
+			t:    time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+			expr: Intersect(Secondly(1), Except(Secondly(1))),
+			next: []time.Time{
+				time.Time{},
+			},
+		},
+		"every minute in December": {
+			t:    time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+			expr: Intersect(Minutely(1), Month(time.December)),
+			next: []time.Time{
+				time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 12, 1, 0, 1, 0, 0, time.UTC),
+			},
+		},
+		"active but never again": {
+			t:    time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+			expr: Intersect(Year(2026), Year(2026)),
+			next: []time.Time{
+				time.Time{},
+			},
+		},
 		"every Friday and Saturday between Jan 1 and Jan 14": {
 			t: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
 			expr: Intersect(
@@ -814,73 +1505,136 @@ func TestUntil(t *testing.T) {
 	}
 }
 
+// goStringTests are shared by the tests of each representation of an
+// expression.
+var goStringTests = map[string]struct {
+	expr Expression
+	want string
+}{
+	"hour": {
+		Hour(15),
+		"te.Hour(15)",
+	},
+	"hourly": {
+		Hourly(2),
+		"te.Hourly(2)",
+	},
+	"minute": {
+		Minute(4),
+		"te.Minute(4)",
+	},
+	"minutely": {
+		Minutely(2),
+		"te.Minutely(2)",
+	},
+	"second": {
+		Second(5),
+		"te.Second(5)",
+	},
+	"secondly": {
+		Secondly(2),
+		"te.Secondly(2)",
+	},
+	"millisecond": {
+		Millisecond(500),
+		"te.Millisecond(500)",
+	},
+	"millisecondly": {
+		Millisecondly(250),
+		"te.Millisecondly(250)",
+	},
+	"microsecond": {
+		Microsecond(500),
+		"te.Microsecond(500)",
+	},
+	"microsecondly": {
+		Microsecondly(250),
+		"te.Microsecondly(250)",
+	},
+	"day": {
+		Day(2),
+		"te.Day(2)",
+	},
+	"weekday": {
+		Weekday(time.Monday),
+		"te.Weekday(time.Monday)",
+	},
+	"month": {
+		Month(time.January),
+		"te.Month(time.January)",
+	},
+	"year": {
+		Year(2016),
+		"te.Year(2016)",
+	},
+	"date range": {
+		DateRange(time.January, 2, time.February, 14),
+		"te.DateRange(time.January, 2, time.February, 14)",
+	},
+	"time range": {
+		TimeRange(6, 0, 0, 7, 0, 0),
+		"te.TimeRange(6, 0, 0, 7, 0, 0)",
+	},
+	"union": {
+		Union(Weekday(time.Tuesday), Weekday(time.Thursday)),
+		"te.Union(te.Weekday(time.Tuesday), te.Weekday(time.Thursday))",
+	},
+	"intersect": {
+		Intersect(Month(time.January), Weekday(time.Tuesday)),
+		"te.Intersect(te.Month(time.January), te.Weekday(time.Tuesday))",
+	},
+	"except": {
+		Except(Weekday(time.Sunday)),
+		"te.Except(te.Weekday(time.Sunday))",
+	},
+	"in": {
+		In(Hour(9), time.UTC),
+		"te.In(te.Hour(9), time.UTC)",
+	},
+	"in location": {
+		In(Hour(9), time.FixedZone("+05:30", 5*60*60+30*60)),
+		`te.In(te.Hour(9), time.FixedZone("+05:30", 19800))`,
+	},
+	"in named location": {
+		In(Hour(9), mustLoadLocation("Europe/London")),
+		`te.In(te.Hour(9), func() *time.Location { loc, _ := time.LoadLocation("Europe/London"); return loc }())`,
+	},
+	"hour step": {
+		HourStep(3, 1),
+		"te.HourStep(3, 1)",
+	},
+	"minute step": {
+		MinuteStep(15, 5),
+		"te.MinuteStep(15, 5)",
+	},
+	"second step": {
+		SecondStep(20, 10),
+		"te.SecondStep(20, 10)",
+	},
+	"every": {
+		Every(90*time.Minute, time.Date(2016, 1, 1, 0, 5, 0, 0, time.UTC)),
+		"te.Every(90*time.Minute, time.Date(2016, time.January, 1, 0, 5, 0, 0, time.UTC))",
+	},
+	"every unit": {
+		Every(time.Hour, time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)),
+		"te.Every(time.Hour, time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC))",
+	},
+	"daily": {
+		Daily(),
+		"te.Daily()",
+	},
+	"last day": {
+		Day(-1),
+		"te.Day(-1)",
+	},
+	"policy": {
+		WithPolicy(Intersect(Weekday(time.Sunday), Time(1, 30, 0)), Policy{Gap: GapSkip, Overlap: OverlapBoth}),
+		"te.Intersect(te.Weekday(time.Sunday), te.Intersect(te.WithPolicy(te.Hour(1), te.Policy{Gap: te.GapSkip, Overlap: te.OverlapBoth}), te.WithPolicy(te.Minute(30), te.Policy{Gap: te.GapSkip, Overlap: te.OverlapBoth}), te.Second(0)))",
+	},
+}
+
 func TestGoString(t *testing.T) {
-	tests := map[string]struct {
-		expr Expression
-		want string
-	}{
-		"hour": {
-			Hour(15),
-			"te.Hour(15)",
-		},
-		"hourly": {
-			Hourly(2),
-			"te.Hourly(2)",
-		},
-		"minute": {
-			Minute(4),
-			"te.Minute(4)",
-		},
-		"minutely": {
-			Minutely(2),
-			"te.Minutely(2)",
-		},
-		"second": {
-			Second(5),
-			"te.Second(5)",
-		},
-		"secondly": {
-			Secondly(2),
-			"te.Secondly(2)",
-		},
-		"day": {
-			Day(2),
-			"te.Day(2)",
-		},
-		"weekday": {
-			Weekday(time.Monday),
-			"te.Weekday(time.Monday)",
-		},
-		"month": {
-			Month(time.January),
-			"te.Month(time.January)",
-		},
-		"year": {
-			Year(2016),
-			"te.Year(2016)",
-		},
-		"date range": {
-			DateRange(time.January, 2, time.February, 14),
-			"te.DateRange(time.January, 2, time.February, 14)",
-		},
-		"time range": {
-			TimeRange(6, 0, 0, 7, 0, 0),
-			"te.TimeRange(6, 0, 0, 7, 0, 0)",
-		},
-		"union": {
-			Union(Weekday(time.Tuesday), Weekday(time.Thursday)),
-			"te.Union(te.Weekday(time.Tuesday), te.Weekday(time.Thursday))",
-		},
-		"intersect": {
-			Intersect(Month(time.January), Weekday(time.Tuesday)),
-			"te.Intersect(te.Month(time.January), te.Weekday(time.Tuesday))",
-		},
-		"except": {
-			Except(Weekday(time.Sunday)),
-			"te.Except(te.Weekday(time.Sunday))",
-		},
-	}
-	for name, tt := range tests {
+	for name, tt := range goStringTests {
 		e, ok := tt.expr.(fmt.GoStringer)
 		if !ok {
 			t.Errorf("%s should be a fmt.Stringer", name)
@@ -903,3 +1657,12 @@ func next(expr Expression, t time.Time, n int) []time.Time {
 	}
 	return ts
 }
+
+// mustLoadLocation returns the named location for tables of tests.
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
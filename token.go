@@ -1,27 +1,41 @@
 package te
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/pnelson/te/internal/tokens"
+)
 
 type tokenType int
 
 const (
 	tokenAnd tokenType = iota
 	tokenAt
+	tokenBetween
 	tokenColon
+	tokenContinuously
 	tokenDaily
+	tokenDash
 	tokenDigit
-	tokenError
+	tokenDot
 	tokenEvery
+	tokenExcept
 	tokenEOF
+	tokenFrom
 	tokenHourly
 	tokenIn
 	tokenLast
+	tokenLeftParen
 	tokenMidnight
 	tokenNoon
 	tokenOf
 	tokenOn
+	tokenOr
 	tokenOrdinal
+	tokenRightParen
+	tokenStarting
 	tokenThe
+	tokenTo
 	tokenTwelveHour
 	tokenWeekday
 	tokenWeekly
@@ -30,12 +44,15 @@ const (
 	tokenQuarterly
 	tokenUnitDay
 	tokenUnitHour
+	tokenUnitMicrosecond
+	tokenUnitMillisecond
 	tokenUnitMinute
 	tokenUnitMonth
 	tokenUnitSecond
 	tokenUnitWeek
 	tokenUnitYear
 	tokenYearly
+	tokenZone
 )
 
 const eof = rune(-1)
@@ -43,27 +60,84 @@ const eof = rune(-1)
 type token struct {
 	typ tokenType
 	val string
+	pos int // byte offset within input
 }
 
 func (t token) String() string {
-	switch t.typ {
-	case tokenError:
-		return t.val
-	case tokenEOF:
+	if t.typ == tokenEOF {
 		return "EOF"
 	}
 	return fmt.Sprintf("%q", t.val)
 }
 
-type parseError struct {
-	token   token
-	message string
+var tokenNames = map[tokenType]string{
+	tokenAnd:             "and",
+	tokenAt:              "at",
+	tokenBetween:         "between",
+	tokenColon:           "colon",
+	tokenContinuously:    "continuously",
+	tokenDaily:           "daily",
+	tokenDash:            "dash",
+	tokenDigit:           "digit",
+	tokenDot:             "dot",
+	tokenEvery:           "every",
+	tokenExcept:          "except",
+	tokenEOF:             "end of input",
+	tokenFrom:            "from",
+	tokenHourly:          "hourly",
+	tokenIn:              "in",
+	tokenLast:            "last",
+	tokenLeftParen:       "opening parenthesis",
+	tokenMidnight:        "midnight",
+	tokenNoon:            "noon",
+	tokenOf:              "of",
+	tokenOn:              "on",
+	tokenOr:              "or",
+	tokenOrdinal:         "ordinal",
+	tokenRightParen:      "closing parenthesis",
+	tokenStarting:        "starting",
+	tokenThe:             "the",
+	tokenTo:              "to",
+	tokenTwelveHour:      "am or pm",
+	tokenWeekday:         "weekday",
+	tokenWeekly:          "weekly",
+	tokenMonth:           "month",
+	tokenMonthly:         "monthly",
+	tokenQuarterly:       "quarterly",
+	tokenUnitDay:         "days",
+	tokenUnitHour:        "hours",
+	tokenUnitMicrosecond: "microseconds",
+	tokenUnitMillisecond: "milliseconds",
+	tokenUnitMinute:      "minutes",
+	tokenUnitMonth:       "months",
+	tokenUnitSecond:      "seconds",
+	tokenUnitWeek:        "weeks",
+	tokenUnitYear:        "years",
+	tokenYearly:          "yearly",
+	tokenZone:            "time zone",
 }
 
-func (e parseError) Error() string {
-	return fmt.Sprintf("%s, token: %q", e.message, e.token.val)
+func (typ tokenType) String() string {
+	if name, ok := tokenNames[typ]; ok {
+		return name
+	}
+	return fmt.Sprintf("tokenType(%d)", int(typ))
 }
 
-func newParseError(t token, message string) parseError {
-	return parseError{t, message}
+func init() {
+	tokens.Lex = lexTokens
+}
+
+// lexTokens returns the tokens of s for the te command, with the default
+// time zone abbreviations.
+func lexTokens(s string) ([]tokens.Token, error) {
+	ts, err := lex(s, abbreviations)
+	if err != nil {
+		return nil, err
+	}
+	rv := make([]tokens.Token, len(ts))
+	for i, t := range ts {
+		rv[i] = tokens.Token{Type: t.typ.String(), Value: t.val, Offset: t.pos}
+	}
+	return rv, nil
 }
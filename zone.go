@@ -0,0 +1,118 @@
+package te
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// abbreviations are the time zone abbreviations recognized by Parse.
+// They are ambiguous and daylight saving time agnostic. For example,
+// both EST and EDT map to America/New_York so that "9am EST" is 9am on
+// the wall clock in New York throughout the year.
+var abbreviations = map[string]string{
+	"UTC":  "UTC",
+	"GMT":  "UTC",
+	"ET":   "America/New_York",
+	"EST":  "America/New_York",
+	"EDT":  "America/New_York",
+	"CT":   "America/Chicago",
+	"CST":  "America/Chicago",
+	"CDT":  "America/Chicago",
+	"MT":   "America/Denver",
+	"MST":  "America/Denver",
+	"MDT":  "America/Denver",
+	"PT":   "America/Los_Angeles",
+	"PST":  "America/Los_Angeles",
+	"PDT":  "America/Los_Angeles",
+	"BST":  "Europe/London",
+	"CET":  "Europe/Paris",
+	"CEST": "Europe/Paris",
+	"IST":  "Asia/Kolkata",
+	"JST":  "Asia/Tokyo",
+	"AEST": "Australia/Sydney",
+	"AEDT": "Australia/Sydney",
+}
+
+// DefaultAbbreviations returns a new map of the upper case time zone
+// abbreviations recognized by Parse to the names of their locations,
+// such as EST to America/New_York. The map may be modified and given
+// to a Parser to configure the abbreviations it recognizes.
+func DefaultAbbreviations() map[string]string {
+	m := make(map[string]string, len(abbreviations))
+	for k, v := range abbreviations {
+		m[k] = v
+	}
+	return m
+}
+
+var errInvalidOffset = errors.New("invalid time zone offset")
+
+// loadLocation returns the location for a time zone qualifier.
+// The name may be one of abbrs, a fixed offset such as +05:30,
+// or a name accepted by time.LoadLocation.
+func loadLocation(name string, abbrs map[string]string) (*time.Location, error) {
+	if name == "" {
+		return nil, errors.New("empty time zone")
+	}
+	if name[0] == '+' || name[0] == '-' {
+		return loadOffset(name)
+	}
+	if s, ok := abbrs[strings.ToUpper(name)]; ok {
+		name = s
+	}
+	return time.LoadLocation(name)
+}
+
+// fixedOffset returns the offset of loc in seconds east of UTC if it is
+// a fixed zone, named for its only abbreviation and never changing offset.
+func fixedOffset(loc *time.Location) (int, bool) {
+	name, offset := time.Date(2000, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	summer, summerOffset := time.Date(2000, time.July, 1, 0, 0, 0, 0, loc).Zone()
+	return offset, name == loc.String() && summer == name && summerOffset == offset
+}
+
+// loadOffset returns a fixed location for offsets of the form
+// +5, +05, +0530 or +05:30.
+func loadOffset(name string) (*time.Location, error) {
+	hh, mm := name[1:], ""
+	if i := strings.IndexByte(hh, ':'); i >= 0 {
+		hh, mm = hh[:i], hh[i+1:]
+		if len(mm) != 2 {
+			return nil, errInvalidOffset
+		}
+	} else if len(hh) == 4 {
+		hh, mm = hh[:2], hh[2:]
+	}
+	if len(hh) == 0 || len(hh) > 2 {
+		return nil, errInvalidOffset
+	}
+	h, err := strconv.Atoi(hh)
+	if err != nil || h > 14 {
+		return nil, errInvalidOffset
+	}
+	m := 0
+	if mm != "" {
+		m, err = strconv.Atoi(mm)
+		if err != nil || m > 59 {
+			return nil, errInvalidOffset
+		}
+	}
+	offset := h*60*60 + m*60
+	if name[0] == '-' {
+		offset = -offset
+	}
+	return time.FixedZone(formatOffset(offset), offset), nil
+}
+
+// formatOffset returns the canonical name of a fixed offset in seconds.
+func formatOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
@@ -0,0 +1,53 @@
+package te
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadLocation(t *testing.T) {
+	tests := []struct {
+		in     string
+		name   string
+		offset int
+	}{
+		{"UTC", "UTC", 0},
+		{"utc", "UTC", 0},
+		{"+5", "+05:00", 5 * 60 * 60},
+		{"+05", "+05:00", 5 * 60 * 60},
+		{"+0530", "+05:30", 5*60*60 + 30*60},
+		{"+05:30", "+05:30", 5*60*60 + 30*60},
+		{"-03:30", "-03:30", -(3*60*60 + 30*60)},
+		{"-00:30", "-00:30", -30 * 60},
+	}
+	for _, tt := range tests {
+		loc, err := loadLocation(tt.in, abbreviations)
+		if err != nil {
+			t.Fatalf("loadLocation(%q) %v", tt.in, err)
+		}
+		name, offset := time.Date(2016, 1, 1, 0, 0, 0, 0, loc).Zone()
+		if loc.String() != tt.name || name != tt.name || offset != tt.offset {
+			t.Errorf("loadLocation(%q)\nhave %s %d\nwant %s %d", tt.in, loc, offset, tt.name, tt.offset)
+		}
+	}
+}
+
+func TestLoadLocationError(t *testing.T) {
+	var tests = []string{
+		"",
+		"+",
+		"+123",
+		"+12345",
+		"+15",
+		"+05:3",
+		"+05:60",
+		"+a",
+		"Nowhere/Special",
+	}
+	for _, tt := range tests {
+		loc, err := loadLocation(tt, abbreviations)
+		if err == nil {
+			t.Errorf("loadLocation(%q)\nhave %v\nwant error", tt, loc)
+		}
+	}
+}